@@ -27,5 +27,10 @@ export GOPATH
 cd "$pchaindir/pchain"
 PWD="$pchaindir/pchain"
 
+# Digest of the consensus critical sources, verified against the release manifest when the validator starts,
+# the same as the digest printed by "pchain verify-vendor"
+digest=$(find vendor/github.com/ethereum/go-ethereum/consensus/tendermint vendor/github.com/ethereum/go-ethereum/core \
+    -name '*.go' ! -name '*_test.go' | LC_ALL=C sort | xargs sha256sum | sha256sum | cut -d' ' -f1)
+
 # build pchain client
-go build -o $root/bin/pchain ./cmd/
+go build -ldflags "-X github.com/pchain/version.ConsensusDigest=$digest" -o $root/bin/pchain ./cmd/
@@ -6,6 +6,7 @@ import (
 	tdmTypes "github.com/ethereum/go-ethereum/consensus/tendermint/types"
 	"github.com/ethereum/go-ethereum/log"
 	eth "github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/pchain/ethereum"
 	"github.com/pchain/version"
 	cfg "github.com/tendermint/go-config"
@@ -81,7 +82,7 @@ func StartChain(ctx *cli.Context, chain *Chain, startDone chan<- struct{}) error
 	return nil
 }
 
-func CreateChildChain(ctx *cli.Context, chainId string, validator tdmTypes.PrivValidator, keyJson []byte, validators []tdmTypes.GenesisValidator) error {
+func CreateChildChain(ctx *cli.Context, chainId string, validator tdmTypes.PrivValidator, keyJson []byte, validators []tdmTypes.GenesisValidator, stateRent *params.StateRentConfig) error {
 
 	// Get Tendermint config base on chain id
 	config := GetTendermintConfig(chainId, ctx)
@@ -102,7 +103,7 @@ func CreateChildChain(ctx *cli.Context, chainId string, validator tdmTypes.PrivV
 	validator.Save()
 
 	// Init the Ethereum Genesis
-	err := initEthGenesisFromExistValidator(chainId, config, validators)
+	err := initEthGenesisFromExistValidator(chainId, config, validators, stateRent)
 	if err != nil {
 		return err
 	}
@@ -111,7 +112,5 @@ func CreateChildChain(ctx *cli.Context, chainId string, validator tdmTypes.PrivV
 	init_eth_blockchain(chainId, config.GetString("eth_genesis_file"), ctx)
 
 	// Init the Tendermint Genesis
-	init_em_files(config, chainId, config.GetString("eth_genesis_file"), validators)
-
-	return nil
+	return init_em_files(config, chainId, config.GetString("eth_genesis_file"), validators)
 }
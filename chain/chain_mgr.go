@@ -13,6 +13,8 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	perrors "github.com/pchain/errors"
 	"github.com/pchain/p2p"
 	"github.com/pchain/rpc"
 	"github.com/pkg/errors"
@@ -21,7 +23,6 @@ import (
 	"gopkg.in/urfave/cli.v1"
 	"io/ioutil"
 	"net"
-	"os"
 	"path"
 	"strconv"
 	"sync"
@@ -128,11 +129,13 @@ func (cm *ChainManager) LoadChains(childIds []string) error {
 	return nil
 }
 
-func (cm *ChainManager) InitCrossChainHelper() {
+func (cm *ChainManager) InitCrossChainHelper() error {
 	cm.cch.chainInfoDB = dbm.NewDB("chaininfo",
 		cm.mainChain.Config.GetString("db_backend"),
 		cm.ctx.GlobalString(utils.DataDirFlag.Name))
 	cm.cch.localTX3CacheDB, _ = ethdb.NewLDBDatabase(path.Join(cm.ctx.GlobalString(utils.DataDirFlag.Name), "tx3cache"), 0, 0)
+	cm.cch.launchScheduler = newChildChainLaunchScheduler(cm.cch.chainInfoDB, cm.ctx.GlobalInt(utils.ChildChainWatchWorkersFlag.Name))
+	cm.cch.stallMonitor = newChildChainStallMonitor(cm.ctx.GlobalDuration(utils.ChildChainStallTimeoutFlag.Name), cm.ctx.GlobalBool(utils.ChildChainStallReleaseFlag.Name))
 
 	chainId := MainChain
 	if cm.ctx.GlobalBool(utils.TestnetFlag.Name) {
@@ -147,11 +150,11 @@ func (cm *ChainManager) InitCrossChainHelper() {
 		url = "http://" + url + "/" + chainId
 		client, err := ethclient.Dial(url)
 		if err != nil {
-			log.Errorf("can't connect to %s, err: %v, exit", url, err)
-			os.Exit(0)
+			return perrors.New(perrors.IO, "chain.InitCrossChainHelper", "can't connect to %s, err: %v", url, err)
 		}
 		cm.cch.client = client
 	}
+	return nil
 }
 
 func (cm *ChainManager) StartP2PServer() error {
@@ -211,6 +214,11 @@ func (cm *ChainManager) StartChains() error {
 
 		// Tell other peers that we have added into a new child chain
 		cm.server.BroadcastNewChildChainMsg(chain.Id)
+
+		// Connect to the peers published in the main chain
+		cm.bootstrapChildChainPeers(chain.Id)
+
+		cm.cch.stallMonitor.watch(chain)
 	}
 
 	return nil
@@ -252,6 +260,20 @@ func (cm *ChainManager) StartRPC() error {
 				}
 			}
 		}
+
+		if rpc.IsFirehoseRunning() {
+			rpc.HookupFirehose(cm.mainChain.Id, MustGetEthereumFromNode(cm.mainChain.EthNode).Firehose())
+			for _, chain := range cm.childChains {
+				rpc.HookupFirehose(chain.Id, MustGetEthereumFromNode(chain.EthNode).Firehose())
+			}
+		}
+
+		if rpc.IsTxStreamRunning() {
+			rpc.HookupTxStream(cm.mainChain.Id, MustGetEthereumFromNode(cm.mainChain.EthNode).TxStream())
+			for _, chain := range cm.childChains {
+				rpc.HookupTxStream(chain.Id, MustGetEthereumFromNode(chain.EthNode).TxStream())
+			}
+		}
 	}
 
 	return nil
@@ -348,9 +370,16 @@ func (cm *ChainManager) LoadChildChainInRT(chainId string) {
 
 	// child chain uses the same validator with the main chain.
 	privValidatorFile := cm.mainChain.Config.GetString("priv_validator_file")
-	self := types.LoadPrivValidator(privValidatorFile)
+	self, err := types.LoadPrivValidator(privValidatorFile)
+	if err != nil {
+		log.Errorf("Load the private validator for Child Chain %v failed! %v", chainId, err)
+		return
+	}
 
-	err := CreateChildChain(cm.ctx, chainId, *self, keyJson, validators)
+	// State Rent is opt-in, nil if the owner does not enable it
+	stateRent := core.GetChildChainStateRent(cm.cch.chainInfoDB, chainId)
+
+	err = CreateChildChain(cm.ctx, chainId, *self, keyJson, validators, stateRent)
 	if err != nil {
 		log.Errorf("Create Child Chain %v failed! %v", chainId, err)
 		return
@@ -400,6 +429,11 @@ func (cm *ChainManager) LoadChildChainInRT(chainId string) {
 	//TODO Broadcast Child ID to all Main Chain peers
 	go cm.server.BroadcastNewChildChainMsg(chainId)
 
+	// Connect to the peers published in the main chain
+	cm.bootstrapChildChainPeers(chainId)
+
+	cm.cch.stallMonitor.watch(chain)
+
 	//hookup rpc
 	if rpc.IsHTTPRunning() {
 		if h, err := chain.EthNode.GetHTTPHandler(); err == nil {
@@ -415,7 +449,50 @@ func (cm *ChainManager) LoadChildChainInRT(chainId string) {
 			log.Errorf("Unable Hook up Child Chain (%v) RPC WS Handler: %v", chainId, err)
 		}
 	}
+	if rpc.IsFirehoseRunning() {
+		rpc.HookupFirehose(chain.Id, MustGetEthereumFromNode(chain.EthNode).Firehose())
+	}
+	if rpc.IsTxStreamRunning() {
+		rpc.HookupTxStream(chain.Id, MustGetEthereumFromNode(chain.EthNode).TxStream())
+	}
+
+}
+
+// bootstrapChildChainPeers connect to the p2p addresses published by the child chain validators in the main chain,
+// so that the child chain node doesn't need to configure its peers manually
+func (cm *ChainManager) bootstrapChildChainPeers(chainId string) {
+
+	validators := core.GetChildChainValidators(cm.cch.chainInfoDB, chainId)
+	if len(validators) == 0 {
+		return
+	}
 
+	stateDB, err := MustGetEthereumFromNode(cm.mainChain.EthNode).BlockChain().State()
+	if err != nil {
+		log.Errorf("Bootstrap Child Chain %v peers failed, can't load main chain state: %v", chainId, err)
+		return
+	}
+
+	srv := cm.server.Server()
+	self := srv.Self()
+	for _, v := range validators {
+		peer := stateDB.GetChildChainPeer(chainId, v.Address)
+		if peer == nil {
+			continue
+		}
+
+		node, err := discover.ParseNode(peer.Enode)
+		if err != nil {
+			log.Warnf("Invalid peer %v of Child Chain %v published by %x: %v", peer.Enode, chainId, v.Address, err)
+			continue
+		}
+		if self != nil && node.ID == self.ID {
+			continue
+		}
+
+		log.Infof("Child Chain %v bootstrap peer %v", chainId, node)
+		srv.AddPeer(node)
+	}
 }
 
 func (cm *ChainManager) formalizeChildChain(chainId string, cci core.CoreChainInfo, ep *epoch.Epoch) {
@@ -437,6 +514,11 @@ func (cm *ChainManager) checkCoinbaseInChildChain(childEpoch *epoch.Epoch) bool
 	return childEpoch.Validators.HasAddress(localEtherbase[:])
 }
 
+// StartStallMonitor starts alerting the child chains running on this node without block for the stall timeout
+func (cm *ChainManager) StartStallMonitor() {
+	cm.cch.stallMonitor.start()
+}
+
 func (cm *ChainManager) WaitChainsStop() {
 
 	<-cm.mainQuit
@@ -446,6 +528,7 @@ func (cm *ChainManager) WaitChainsStop() {
 }
 
 func (cm *ChainManager) Stop() {
+	cm.cch.stallMonitor.stop()
 	rpc.StopRPC()
 	cm.server.Stop()
 }
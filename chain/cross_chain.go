@@ -12,9 +12,11 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
@@ -36,6 +38,10 @@ const (
 	OFFICIAL_MINIMUM_DEPOSIT    = "100000000000000000000000" // 100,000 * e18
 )
 
+// settleResendBlocks is the number of the main chain blocks to wait before sending the settlement of the expired
+// cross chain transfer again
+const settleResendBlocks = 10
+
 type CrossChainHelper struct {
 	mtx             sync.Mutex
 	chainInfoDB     dbm.DB
@@ -43,6 +49,17 @@ type CrossChainHelper struct {
 	//the client does only connect to main chain
 	client      *ethclient.Client
 	mainChainId string
+
+	// expired cross chain transfers being settled by this node -> main chain height when the settlement is sent
+	settleMtx sync.Mutex
+	settling  map[common.Hash]uint64
+
+	launchScheduler *childChainLaunchScheduler
+	stallMonitor    *childChainStallMonitor
+
+	// hashes of the child chain proofs verified in the last batch, their commits are not verified again
+	proofMtx       sync.Mutex
+	verifiedProofs map[common.Hash]struct{}
 }
 
 func (cch *CrossChainHelper) GetMutex() *sync.Mutex {
@@ -222,7 +239,12 @@ func (cch *CrossChainHelper) JoinChildChain(from common.Address, pubkey crypto.P
 func (cch *CrossChainHelper) ReadyForLaunchChildChain(height *big.Int, stateDB *state.StateDB) ([]string, []byte, []string) {
 	log.Debug("ReadyForLaunchChildChain - start")
 
-	readyId, updateBytes, removedId := core.GetChildChainForLaunch(cch.chainInfoDB, height, stateDB)
+	plan := cch.launchScheduler.get(height)
+	plan.Apply(stateDB)
+	// Compute the decision of the next block ahead, off the finalization of the block
+	cch.launchScheduler.next(height)
+
+	readyId, updateBytes, removedId := plan.ReadyForLaunch, plan.NewPendingIdxBytes, plan.DeleteChildChainIds
 	if len(readyId) == 0 {
 		log.Debugf("ReadyForLaunchChildChain - No child chain to be launch in Block %v", height)
 	} else {
@@ -277,6 +299,55 @@ func (cch *CrossChainHelper) RevealVote(ep *epoch.Epoch, from common.Address, pu
 	return nil
 }
 
+// DepositTopUp add the top-up amount into the revealed vote, so that the vote amount will not override the top-up
+func (cch *CrossChainHelper) DepositTopUp(ep *epoch.Epoch, from common.Address, amount *big.Int) error {
+
+	nextEp := ep.GetNextEpoch()
+	if nextEp == nil || nextEp.GetEpochValidatorVoteSet() == nil {
+		return nil
+	}
+
+	voteSet := nextEp.GetEpochValidatorVoteSet()
+	if vote, exist := voteSet.GetVoteByAddress(from); exist && vote.Amount != nil {
+		vote.Amount = new(big.Int).Add(vote.Amount, amount)
+		// Save the VoteSet
+		epoch.SaveEpochVoteSet(ep.GetDB(), nextEp.Number, voteSet)
+	}
+	return nil
+}
+
+// VoluntaryExit add the validator into the exit set, it will be removed from the next epoch
+func (cch *CrossChainHelper) VoluntaryExit(ep *epoch.Epoch, from common.Address) error {
+
+	exitSet := epoch.LoadEpochValidatorExitSet(ep.GetDB(), ep.Number+1)
+	exitSet.AddExit(from)
+	// Save the ExitSet
+	epoch.SaveEpochValidatorExitSet(ep.GetDB(), ep.Number+1, exitSet)
+	return nil
+}
+
+// Unjail add the revealed vote of the unjailed validator into the vote set, it will rejoin the next epoch
+func (cch *CrossChainHelper) Unjail(ep *epoch.Epoch, from common.Address, pubkey crypto.PubKey, depositAmount *big.Int, txHash common.Hash) error {
+
+	voteSet := ep.GetNextEpoch().GetEpochValidatorVoteSet()
+	vote, exist := voteSet.GetVoteByAddress(from)
+
+	if !exist {
+		vote = &epoch.EpochValidatorVote{
+			Address: from,
+		}
+		voteSet.StoreVote(vote)
+	}
+	// Overwrite the Previous Vote, the vote of the jailed validator was rejected
+	vote.PubKey = pubkey
+	vote.Amount = depositAmount
+	vote.Salt = "unjail"
+	vote.TxHash = txHash
+	// Save the VoteSet
+	epoch.SaveEpochVoteSet(ep.GetDB(), ep.GetNextEpoch().Number, voteSet)
+	return nil
+}
+
 func (cch *CrossChainHelper) GetHeightFromMainChain() *big.Int {
 	ethereum := MustGetEthereumFromNode(chainMgr.mainChain.EthNode)
 	return ethereum.BlockChain().CurrentBlock().Number()
@@ -299,6 +370,76 @@ func (cch *CrossChainHelper) GetEpochFromMainChain() (string, *epoch.Epoch) {
 	return ethereum.ChainConfig().PChainId, ep
 }
 
+// GetChainStateAndHeader returns the state and the header of the block of the main chain or the child chain running
+// on this node, the latest block if number is nil
+func (cch *CrossChainHelper) GetChainStateAndHeader(chainId string, number *big.Int) (*state.StateDB, *types.Header, error) {
+	chain := chainMgr.mainChain
+	if chainId != chain.Id {
+		var ok bool
+		if chain, ok = chainMgr.childChains[chainId]; !ok || chain.EthNode == nil {
+			return nil, nil, fmt.Errorf("chain %s is not running on this node", chainId)
+		}
+	}
+
+	ethereum, err := getEthereumFromNode(chain.EthNode)
+	if err != nil {
+		return nil, nil, err
+	}
+	header := ethereum.BlockChain().CurrentHeader()
+	if number != nil {
+		header = ethereum.BlockChain().GetHeaderByNumber(number.Uint64())
+	}
+	if header == nil {
+		return nil, nil, fmt.Errorf("block #%v of chain %s not found", number, chainId)
+	}
+	stateDB, err := ethereum.BlockChain().StateAt(header.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stateDB, header, nil
+}
+
+// GetChainTransaction returns the tx of the chain running on this node with its position in the chain, nil if the tx
+// is not found in the chain
+func (cch *CrossChainHelper) GetChainTransaction(chainId string, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	chain := chainMgr.mainChain
+	if chainId != chain.Id {
+		var ok bool
+		if chain, ok = chainMgr.childChains[chainId]; !ok || chain.EthNode == nil {
+			return nil, common.Hash{}, 0, 0, fmt.Errorf("chain %s is not running on this node", chainId)
+		}
+	}
+
+	ethereum, err := getEthereumFromNode(chain.EthNode)
+	if err != nil {
+		return nil, common.Hash{}, 0, 0, err
+	}
+	tx, blockHash, blockNumber, index := core.GetTransaction(ethereum.ChainDb(), txHash)
+	return tx, blockHash, blockNumber, index, nil
+}
+
+// SubscribeChainEvent subscribes to the blocks inserted into the chain running on this node
+func (cch *CrossChainHelper) SubscribeChainEvent(chainId string, ch chan<- core.ChainEvent) (event.Subscription, error) {
+	chain := chainMgr.mainChain
+	if chainId != chain.Id {
+		var ok bool
+		if chain, ok = chainMgr.childChains[chainId]; !ok || chain.EthNode == nil {
+			return nil, fmt.Errorf("chain %s is not running on this node", chainId)
+		}
+	}
+
+	ethereum, err := getEthereumFromNode(chain.EthNode)
+	if err != nil {
+		return nil, err
+	}
+	return ethereum.BlockChain().SubscribeChainEvent(ch), nil
+}
+
+// GetChainStall returns the stall of the child chain reported by the stall monitor
+func (cch *CrossChainHelper) GetChainStall(chainId string) (time.Time, bool) {
+	return cch.stallMonitor.stalledSince(chainId)
+}
+
 func (cch *CrossChainHelper) ChangeValidators(chainId string) {
 
 	if chainMgr == nil {
@@ -327,42 +468,103 @@ func (cch *CrossChainHelper) VerifyChildChainProofData(bs []byte) error {
 
 	log.Debug("VerifyChildChainProofData - start")
 
+	cv, err := cch.checkChildChainProofData(bs)
+	if err != nil {
+		return err
+	}
+	if cv != nil && !cch.isProofVerified(bs) {
+		if err = cv.ValSet.VerifyCommit(cv.ChainID, cv.Height, cv.Commit); err != nil {
+			return err
+		}
+	}
+
+	log.Debug("VerifyChildChainProofData - end")
+	return nil
+}
+
+// VerifyChildChainProofDataBatch verifies the proofs like VerifyChildChainProofData, the signatures of the commits
+// are verified in one batch, the main chain may ingest the checkpoints of many child chains at once. The error of
+// the i-th proof is returned at index i, the valid proofs are remembered until the next batch, so that their
+// commits are not verified again when the transactions carrying them are validated one by one
+func (cch *CrossChainHelper) VerifyChildChainProofDataBatch(bss [][]byte) []error {
+
+	errs := make([]error, len(bss))
+	var indexes []int
+	var commits []tdmTypes.CommitVerification
+	for i, bs := range bss {
+		cv, err := cch.checkChildChainProofData(bs)
+		if err != nil {
+			errs[i] = err
+		} else if cv != nil {
+			indexes = append(indexes, i)
+			commits = append(commits, *cv)
+		}
+	}
+
+	verified := make(map[common.Hash]struct{})
+	for k, err := range tdmTypes.BatchVerifyCommits(commits) {
+		if err != nil {
+			errs[indexes[k]] = err
+		} else {
+			verified[ethcrypto.Keccak256Hash(bss[indexes[k]])] = struct{}{}
+		}
+	}
+
+	cch.proofMtx.Lock()
+	cch.verifiedProofs = verified
+	cch.proofMtx.Unlock()
+	return errs
+}
+
+// isProofVerified returns true if the commit of the proof has been verified by VerifyChildChainProofDataBatch
+func (cch *CrossChainHelper) isProofVerified(bs []byte) bool {
+	cch.proofMtx.Lock()
+	defer cch.proofMtx.Unlock()
+
+	_, ok := cch.verifiedProofs[ethcrypto.Keccak256Hash(bs)]
+	return ok
+}
+
+// checkChildChainProofData checks the proof except the signature of the commit, returns the commit to be verified,
+// nil if the commit of the block is not verified
+func (cch *CrossChainHelper) checkChildChainProofData(bs []byte) (*tdmTypes.CommitVerification, error) {
+
 	var proofData types.ChildChainProofData
 	err := rlp.DecodeBytes(bs, &proofData)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	header := proofData.Header
 	// Don't waste time checking blocks from the future
 	if header.Time.Cmp(big.NewInt(time.Now().Unix())) > 0 {
-		return errors.New("block in the future")
+		return nil, errors.New("block in the future")
 	}
 
 	tdmExtra, err := tdmTypes.ExtractTendermintExtra(header)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	chainId := tdmExtra.ChainID
 	if chainId == "" || chainId == MainChain || chainId == TestnetChain {
-		return fmt.Errorf("invalid child chain id: %s", chainId)
+		return nil, fmt.Errorf("invalid child chain id: %s", chainId)
 	}
 
 	if header.Nonce != (types.TendermintEmptyNonce) && !bytes.Equal(header.Nonce[:], types.TendermintNonce) {
-		return errors.New("invalid nonce")
+		return nil, errors.New("invalid nonce")
 	}
 
 	if header.MixDigest != types.TendermintDigest {
-		return errors.New("invalid mix digest")
+		return nil, errors.New("invalid mix digest")
 	}
 
 	if header.UncleHash != types.TendermintNilUncleHash {
-		return errors.New("invalid uncle Hash")
+		return nil, errors.New("invalid uncle Hash")
 	}
 
 	if header.Difficulty == nil || header.Difficulty.Cmp(types.TendermintDefaultDifficulty) != 0 {
-		return errors.New("invalid difficulty")
+		return nil, errors.New("invalid difficulty")
 	}
 
 	// special case: epoch 0 update
@@ -370,7 +572,7 @@ func (cch *CrossChainHelper) VerifyChildChainProofData(bs []byte) error {
 	if tdmExtra.EpochBytes != nil && len(tdmExtra.EpochBytes) != 0 {
 		ep := epoch.FromBytes(tdmExtra.EpochBytes)
 		if ep != nil && ep.Number == 0 {
-			return nil
+			return nil, nil
 		}
 	}
 
@@ -378,29 +580,30 @@ func (cch *CrossChainHelper) VerifyChildChainProofData(bs []byte) error {
 	if chainId != "child_0" {
 		ci := core.GetChainInfo(cch.chainInfoDB, chainId)
 		if ci == nil {
-			return fmt.Errorf("chain info %s not found", chainId)
+			return nil, fmt.Errorf("chain info %s not found", chainId)
 		}
 		epoch := ci.GetEpochByBlockNumber(tdmExtra.Height)
 		if epoch == nil {
-			return fmt.Errorf("could not get epoch for block height %v", tdmExtra.Height)
+			return nil, fmt.Errorf("could not get epoch for block height %v", tdmExtra.Height)
 		}
 		valSet := epoch.Validators
 		if !bytes.Equal(valSet.Hash(), tdmExtra.ValidatorsHash) {
-			return errors.New("inconsistent validator set")
+			return nil, errors.New("inconsistent validator set")
 		}
 
 		seenCommit := tdmExtra.SeenCommit
 		if !bytes.Equal(tdmExtra.SeenCommitHash, seenCommit.Hash()) {
-			return errors.New("invalid committed seals")
+			return nil, errors.New("invalid committed seals")
 		}
 
-		if err = valSet.VerifyCommit(tdmExtra.ChainID, tdmExtra.Height, seenCommit); err != nil {
-			return err
-		}
+		return &tdmTypes.CommitVerification{
+			ChainID: tdmExtra.ChainID,
+			Height:  tdmExtra.Height,
+			ValSet:  valSet,
+			Commit:  seenCommit,
+		}, nil
 	}
-
-	log.Debug("VerifyChildChainProofData - end")
-	return nil
+	return nil, nil
 }
 
 func (cch *CrossChainHelper) SaveChildChainProofDataToMainChain(bs []byte) error {
@@ -454,10 +657,586 @@ func (cch *CrossChainHelper) SaveChildChainProofDataToMainChain(bs []byte) error
 		}
 	}
 
+	// Validators signed the checkpoint are online, record their attendance during the launch grace period
+	if la := core.GetLaunchAttendance(cch.chainInfoDB, chainId); la != nil && !la.Settled {
+		if ci := core.GetChainInfo(cch.chainInfoDB, chainId); ci != nil {
+			if ep := ci.GetEpochByBlockNumber(tdmExtra.Height); ep != nil && tdmExtra.SeenCommit != nil {
+				if la.MarkAttended(ep.Validators.Signers(tdmExtra.SeenCommit.BitArray)) {
+					core.SaveLaunchAttendance(cch.chainInfoDB, la)
+					log.Infof("Launch attendance saved from chain: %s, block: %v, absent: %x", chainId, tdmExtra.Height, la.Absent())
+				}
+			}
+		}
+	}
+
+	// The withdrawals in the sealed exit batches of the child chain are claimed against the state root of the checkpoint
+	core.SaveExitCheckpoint(cch.chainInfoDB, chainId, tdmExtra.Height, header.Root)
+
+	// Final checkpoint of the decommissioned child chain, start the claims period
+	di := core.GetDecommissionInfo(cch.chainInfoDB, chainId)
+	if di != nil && di.Status == core.DecommissionApproved && tdmExtra.Height == di.FinalBlock {
+		di.FinalStateRoot = header.Root
+		di.ClaimsEnd = header.Time.Uint64() + core.DecommissionClaimsPeriod
+		di.Status = core.DecommissionClaiming
+		core.SaveDecommissionInfo(cch.chainInfoDB, di)
+		log.Infof("Final checkpoint saved from chain: %s, block: %v, state root: %x", chainId, di.FinalBlock, di.FinalStateRoot)
+	}
+
 	log.Debug("SaveChildChainProofDataToMainChain - end")
 	return nil
 }
 
+// CanDecommissionChildChain check the condition before send the decommission child chain into the tx pool, the
+// decommission is proposed by the owner and then approved by the child chain validators with the same final block
+func (cch *CrossChainHelper) CanDecommissionChildChain(from common.Address, chainId string, finalBlock *big.Int) error {
+
+	ci := core.GetChainInfo(cch.chainInfoDB, chainId)
+	if ci == nil {
+		return fmt.Errorf("child chain %s not exist or not started", chainId)
+	}
+
+	if finalBlock == nil || finalBlock.Sign() <= 0 || !finalBlock.IsUint64() {
+		return errors.New("invalid final block number")
+	}
+
+	di := core.GetDecommissionInfo(cch.chainInfoDB, chainId)
+	if di == nil {
+		// Proposal
+		if ci.Owner != from {
+			return core.ErrNotOwner
+		}
+
+		// The final checkpoint could not be earlier than the latest epoch we received from the child chain
+		if ci.Epoch != nil && finalBlock.Uint64() < ci.Epoch.StartBlock {
+			return fmt.Errorf("final block %v is before the start block %v of the current epoch", finalBlock, ci.Epoch.StartBlock)
+		}
+		return nil
+	}
+
+	// Approval
+	if di.IsApproved() {
+		return core.ErrChildChainDecommissioned
+	}
+
+	if finalBlock.Uint64() != di.FinalBlock {
+		return fmt.Errorf("final block %v mismatch the proposed final block %v", finalBlock, di.FinalBlock)
+	}
+
+	if _, ok := decommissionVotingPower(ci)[from]; !ok {
+		return core.ErrNotChildChainValidator
+	}
+
+	if di.HasApproved(from) {
+		return core.ErrDecommissionApproved
+	}
+
+	return nil
+}
+
+// DecommissionChildChain Save the Decommission Info into the DB, the proposal of the owner or the approval of the
+// validator is recorded. Once approved by +2/3 voting power, the child chain will stop after the final block
+func (cch *CrossChainHelper) DecommissionChildChain(from common.Address, chainId string, finalBlock uint64) error {
+	log.Debug("DecommissionChildChain - start")
+
+	ci := core.GetChainInfo(cch.chainInfoDB, chainId)
+	if ci == nil {
+		return fmt.Errorf("child chain %s not exist or not started", chainId)
+	}
+
+	di := core.GetDecommissionInfo(cch.chainInfoDB, chainId)
+	if di == nil {
+		di = &core.DecommissionInfo{
+			ChainId:    chainId,
+			FinalBlock: finalBlock,
+			Status:     core.DecommissionProposed,
+		}
+		log.Infof("DecommissionChildChain - child chain %s proposed to be decommissioned by %x, final block %v", chainId, from, finalBlock)
+	}
+
+	// The proposal of the owner counts as approval if it's also a validator
+	votingPower := decommissionVotingPower(ci)
+	if _, ok := votingPower[from]; ok && !di.HasApproved(from) {
+		di.Approvals = append(di.Approvals, from)
+		log.Infof("DecommissionChildChain - decommission of child chain %s approved by validator %x", chainId, from)
+	}
+
+	totalPower, approvedPower := new(big.Int), new(big.Int)
+	for _, power := range votingPower {
+		totalPower.Add(totalPower, power)
+	}
+	for _, approval := range di.Approvals {
+		approvedPower.Add(approvedPower, votingPower[approval])
+	}
+	if approvedPower.Mul(approvedPower, big.NewInt(3)).Cmp(totalPower.Mul(totalPower, big.NewInt(2))) > 0 {
+		di.Status = core.DecommissionApproved
+		log.Infof("DecommissionChildChain - decommission of child chain %s approved, final block %v", chainId, di.FinalBlock)
+	}
+
+	core.SaveDecommissionInfo(cch.chainInfoDB, di)
+
+	log.Debug("DecommissionChildChain - end")
+	return nil
+}
+
+// decommissionVotingPower returns the voting power of the child chain validators known by the main chain, the
+// joined validators vote with their deposit if the child chain has not saved any epoch to the main chain yet
+func decommissionVotingPower(ci *core.ChainInfo) map[common.Address]*big.Int {
+	votingPower := make(map[common.Address]*big.Int)
+	if ci.Epoch != nil && ci.Epoch.Validators != nil {
+		for _, val := range ci.Epoch.Validators.Validators {
+			votingPower[common.BytesToAddress(val.Address)] = val.VotingPower
+		}
+		return votingPower
+	}
+	for _, jv := range ci.JoinedValidators {
+		votingPower[jv.Address] = jv.DepositAmount
+	}
+	return votingPower
+}
+
+// ValidateChildChainAssetClaim verify the account proof against the final state root of the decommissioned child chain,
+// return the balance of the account in the final state
+func (cch *CrossChainHelper) ValidateChildChainAssetClaim(from common.Address, chainId string, proof []byte) (*big.Int, error) {
+
+	di := core.GetDecommissionInfo(cch.chainInfoDB, chainId)
+	if di == nil {
+		return nil, fmt.Errorf("child chain %s is not decommissioned", chainId)
+	}
+
+	if di.Status != core.DecommissionClaiming {
+		return nil, core.ErrNotInClaimsPeriod
+	}
+
+	if core.HasClaimedChildChainAsset(cch.chainInfoDB, chainId, from) {
+		return nil, core.ErrAssetClaimed
+	}
+
+	var nodes [][]byte
+	if err := rlp.DecodeBytes(proof, &nodes); err != nil {
+		return nil, err
+	}
+
+	proofDb, _ := ethdb.NewMemDatabase()
+	for _, node := range nodes {
+		proofDb.Put(ethcrypto.Keccak256(node), node)
+	}
+
+	value, err, _ := trie.VerifyProof(di.FinalStateRoot, ethcrypto.Keccak256(from.Bytes()), proofDb)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset proof: %v", err)
+	}
+	if value == nil {
+		return nil, fmt.Errorf("account %x not found in the final state of chain %s", from, chainId)
+	}
+
+	var account state.Account
+	if err := rlp.DecodeBytes(value, &account); err != nil {
+		return nil, err
+	}
+
+	return account.Balance, nil
+}
+
+// ClaimChildChainAsset mark the asset of the account has been claimed, the claim is paid at the end of the claims period
+func (cch *CrossChainHelper) ClaimChildChainAsset(chainId string, account common.Address, amount *big.Int) error {
+	core.SaveChildChainAssetClaim(cch.chainInfoDB, chainId, core.AssetClaim{Account: account, Amount: amount})
+	return nil
+}
+
+// ReadyForReleaseChildChain pay the asset claims and release the deposits of the child chain validators when the claims
+// period ended. The escrow of the child chain is shared out to the claims in proportion to the claimed balances if
+// it can't pay them all, the balances minted in the child chain are not backed by the escrow
+func (cch *CrossChainHelper) ReadyForReleaseChildChain(blockTime *big.Int, stateDB *state.StateDB) []string {
+
+	var releasedId []string
+	for _, di := range core.GetDecommissionInfos(cch.chainInfoDB) {
+		if di.Status != core.DecommissionClaiming || blockTime.Uint64() < di.ClaimsEnd {
+			continue
+		}
+
+		ci := core.GetChainInfo(cch.chainInfoDB, di.ChainId)
+		if ci == nil {
+			continue
+		}
+
+		// The validator deposits are kept in the chain balance, the rest is the escrow of the holders
+		escrow := new(big.Int).Sub(stateDB.GetChainBalance(ci.Owner), ci.TotalDeposit())
+		if escrow.Sign() < 0 {
+			escrow.SetInt64(0)
+		}
+		claims := core.GetChildChainAssetClaims(cch.chainInfoDB, di.ChainId)
+		totalClaimed := new(big.Int)
+		for _, claim := range claims {
+			totalClaimed.Add(totalClaimed, claim.Amount)
+		}
+		for _, claim := range claims {
+			amount := claim.Amount
+			if totalClaimed.Cmp(escrow) > 0 {
+				amount = new(big.Int).Div(new(big.Int).Mul(claim.Amount, escrow), totalClaimed)
+			}
+			stateDB.SubChainBalance(ci.Owner, amount)
+			stateDB.AddBalance(claim.Account, amount)
+		}
+
+		// Deposit was moved to the Child Chain Account during launch, move them back
+		for _, jv := range ci.JoinedValidators {
+			amount := math.BigMin(jv.DepositAmount, stateDB.GetChainBalance(ci.Owner))
+			stateDB.SubChainBalance(ci.Owner, amount)
+			stateDB.AddBalance(jv.Address, amount)
+		}
+		releasedId = append(releasedId, di.ChainId)
+	}
+
+	if len(releasedId) > 0 {
+		log.Infof("ReadyForReleaseChildChain - %v child chain(s) to be released at %v. %v", len(releasedId), blockTime, releasedId)
+	}
+	return releasedId
+}
+
+// ReleaseChildChains close the decommission of the child chains
+func (cch *CrossChainHelper) ReleaseChildChains(chainIds []string) {
+	for _, chainId := range chainIds {
+		di := core.GetDecommissionInfo(cch.chainInfoDB, chainId)
+		if di == nil {
+			continue
+		}
+		di.Status = core.DecommissionReleased
+		core.SaveDecommissionInfo(cch.chainInfoDB, di)
+	}
+}
+
+func (cch *CrossChainHelper) getLaunchGracePeriod() uint64 {
+	ethereum := MustGetEthereumFromNode(chainMgr.mainChain.EthNode)
+	return ethereum.ChainConfig().Tendermint.GetLaunchGracePeriod()
+}
+
+// TrackLaunchAttendance start tracking the attendance of the validators of the launched child chains
+func (cch *CrossChainHelper) TrackLaunchAttendance(chainIds []string, launchHeight uint64) {
+
+	gracePeriod := cch.getLaunchGracePeriod()
+	if gracePeriod == 0 {
+		return
+	}
+
+	for _, chainId := range chainIds {
+		// Pending data is removed after the child chain is formalized
+		cci := core.GetPendingChildChainData(cch.chainInfoDB, chainId)
+		if cci == nil {
+			continue
+		}
+
+		la := &core.LaunchAttendance{
+			ChainId:    chainId,
+			GraceEnd:   launchHeight + gracePeriod,
+			Validators: make([]common.Address, len(cci.JoinedValidators)),
+			Attended:   make([]bool, len(cci.JoinedValidators)),
+		}
+		for i, jv := range cci.JoinedValidators {
+			la.Validators[i] = jv.Address
+		}
+		core.SaveLaunchAttendance(cch.chainInfoDB, la)
+	}
+}
+
+// ReadyForPenalizeAbsentValidators slash the deposits of the validators who are still absent when the launch grace
+// period ended, the rest of the deposits are refunded
+func (cch *CrossChainHelper) ReadyForPenalizeAbsentValidators(height *big.Int, stateDB *state.StateDB) []string {
+
+	var penalizedId []string
+	for _, la := range core.GetLaunchAttendances(cch.chainInfoDB) {
+		if la.Settled || height.Uint64() < la.GraceEnd {
+			continue
+		}
+
+		ci := core.GetChainInfo(cch.chainInfoDB, la.ChainId)
+		if ci == nil {
+			continue
+		}
+
+		// Deposit was moved to the Child Chain Account during launch, the slashed part is burnt
+		for _, absent := range la.Absent() {
+			for _, jv := range ci.JoinedValidators {
+				if jv.Address != absent {
+					continue
+				}
+				amount := math.BigMin(jv.DepositAmount, stateDB.GetChainBalance(ci.Owner))
+				stateDB.SubChainBalance(ci.Owner, amount)
+
+				slash := new(big.Int).Div(new(big.Int).Mul(jv.DepositAmount, big.NewInt(core.LaunchAbsenceSlashPercent)), big.NewInt(100))
+				if refund := new(big.Int).Sub(amount, slash); refund.Sign() > 0 {
+					stateDB.AddBalance(jv.Address, refund)
+				}
+			}
+		}
+		penalizedId = append(penalizedId, la.ChainId)
+	}
+
+	if len(penalizedId) > 0 {
+		log.Infof("ReadyForPenalizeAbsentValidators - %v child chain(s) ended the launch grace period at %v. %v", len(penalizedId), height, penalizedId)
+	}
+	return penalizedId
+}
+
+// PenalizeAbsentValidators close the launch attendance of the child chains, the seats of the absent validators are
+// offered to the next candidates of the child chain in the next epoch
+func (cch *CrossChainHelper) PenalizeAbsentValidators(chainIds []string) {
+	for _, chainId := range chainIds {
+		la := core.GetLaunchAttendance(cch.chainInfoDB, chainId)
+		if la == nil {
+			continue
+		}
+		la.Settled = true
+		core.SaveLaunchAttendance(cch.chainInfoDB, la)
+
+		absent := la.Absent()
+		if len(absent) == 0 {
+			continue
+		}
+
+		// Deposits of the absent validators have been settled, they should not be released again in decommission
+		if ci := core.GetChainInfo(cch.chainInfoDB, chainId); ci != nil {
+			joined := ci.JoinedValidators[:0]
+			for _, jv := range ci.JoinedValidators {
+				if !containsAddress(absent, jv.Address) {
+					joined = append(joined, jv)
+				}
+			}
+			ci.JoinedValidators = joined
+			core.SaveChainInfo(cch.chainInfoDB, ci)
+		}
+		log.Infof("PenalizeAbsentValidators - child chain %s, absent validators %x", chainId, absent)
+
+		// Remove the absent validators from the child chain in the next epoch, as they exit voluntarily
+		if chainMgr == nil {
+			continue
+		}
+		chn, ok := chainMgr.childChains[chainId]
+		if !ok || chn.EthNode == nil {
+			continue
+		}
+		if tdm, ok := MustGetEthereumFromNode(chn.EthNode).Engine().(consensus.Tendermint); ok {
+			if ep := tdm.GetEpoch(); ep != nil {
+				exitSet := epoch.LoadEpochValidatorExitSet(ep.GetDB(), ep.Number+1)
+				for _, addr := range absent {
+					exitSet.AddExit(addr)
+				}
+				epoch.SaveEpochValidatorExitSet(ep.GetDB(), ep.Number+1, exitSet)
+			}
+		}
+	}
+}
+
+func containsAddress(addrs []common.Address, addr common.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// CanSetChildChainStateRent check the condition before enable the state rent of the child chain
+func (cch *CrossChainHelper) CanSetChildChainStateRent(from common.Address, chainId string, period, accountRent, codeByteRent *big.Int) error {
+
+	// State rent must be decided before the child chain genesis is created
+	cci := core.GetPendingChildChainData(cch.chainInfoDB, chainId)
+	if cci == nil {
+		return fmt.Errorf("child chain %s not exist or already launched", chainId)
+	}
+
+	if cci.Owner != from {
+		return core.ErrNotOwner
+	}
+
+	if period == nil || period.Sign() <= 0 || !period.IsUint64() {
+		return errors.New("invalid state rent period")
+	}
+
+	if accountRent == nil || accountRent.Sign() < 0 || codeByteRent == nil || codeByteRent.Sign() < 0 {
+		return errors.New("state rent can not be negative")
+	}
+
+	return nil
+}
+
+// SetChildChainStateRent Save the State Rent Config into the DB, it will be applied to the child chain genesis during launch
+func (cch *CrossChainHelper) SetChildChainStateRent(from common.Address, chainId string, period uint64, accountRent, codeByteRent *big.Int) error {
+	log.Debug("SetChildChainStateRent - start")
+
+	core.SaveChildChainStateRent(cch.chainInfoDB, chainId, &params.StateRentConfig{
+		Period:       period,
+		AccountRent:  accountRent,
+		CodeByteRent: codeByteRent,
+	})
+	log.Infof("SetChildChainStateRent - state rent of child chain %s enabled by %x, period %v", chainId, from, period)
+
+	log.Debug("SetChildChainStateRent - end")
+	return nil
+}
+
+// GetCrossChainTransferTimeout returns the timeout window (in main chain blocks) of the deposit to child chain
+func (cch *CrossChainHelper) GetCrossChainTransferTimeout() uint64 {
+	ethereum := MustGetEthereumFromNode(chainMgr.mainChain.EthNode)
+	return ethereum.ChainConfig().Tendermint.GetCrossChainTransferTimeout()
+}
+
+// LockCrossChainTransfer add the deposit into the pending list, it will be settled after the timeout
+func (cch *CrossChainHelper) LockCrossChainTransfer(txHash common.Hash, chainId string, from common.Address, lockHeight uint64) error {
+	core.AddPendingTransfer(cch.chainInfoDB, core.PendingTransfer{
+		TxHash:     txHash,
+		ChainId:    chainId,
+		From:       from,
+		LockHeight: lockHeight,
+	})
+	return nil
+}
+
+// SettleCrossChainTransfer remove the settled deposit from the pending list
+func (cch *CrossChainHelper) SettleCrossChainTransfer(txHash common.Hash) error {
+	core.RemovePendingTransfer(cch.chainInfoDB, txHash)
+
+	cch.settleMtx.Lock()
+	delete(cch.settling, txHash)
+	cch.settleMtx.Unlock()
+	return nil
+}
+
+// IsDepositExpired check if the deposit could no longer be accepted by the child chain.
+// The child chain stops accepting the deposit a bit (1/10 of the timeout) earlier than the timeout,
+// so that the deposit won't be refunded in the main chain after it has been delivered
+func (cch *CrossChainHelper) IsDepositExpired(txHash common.Hash) bool {
+	ethereum := MustGetEthereumFromNode(chainMgr.mainChain.EthNode)
+	stateDB, err := ethereum.BlockChain().State()
+	if err != nil {
+		return true
+	}
+
+	status, lockHeight := stateDB.GetCrossChainTransfer(txHash)
+	switch status {
+	case state.CrossChainTransferRefunded:
+		return true
+	case state.CrossChainTransferLocked:
+		timeout := cch.GetCrossChainTransferTimeout()
+		if timeout == 0 {
+			return false
+		}
+		return ethereum.BlockChain().CurrentBlock().NumberU64()+timeout/10 >= lockHeight+timeout
+	}
+	return false
+}
+
+// GetDepositStatusInChildChain check if the deposit has been submitted (relayed) or executed (minted) in the child chain,
+// the child chain must be running on this node
+func (cch *CrossChainHelper) GetDepositStatusInChildChain(chainId string, from common.Address, txHash common.Hash) (relayed, minted bool, err error) {
+	chain, ok := chainMgr.childChains[chainId]
+	if !ok || chain.EthNode == nil {
+		return false, false, fmt.Errorf("child chain %s is not running on this node", chainId)
+	}
+
+	ethereum, err := getEthereumFromNode(chain.EthNode)
+	if err != nil {
+		return false, false, err
+	}
+
+	stateDB, err := ethereum.BlockChain().State()
+	if err != nil {
+		return false, false, err
+	}
+	if stateDB.HasTX1(from, txHash) {
+		return true, true, nil
+	}
+
+	// the deposit could be delivered by any relayer, not only the account
+	pending, queued := ethereum.TxPool().Content()
+	for _, content := range []map[common.Address]types.Transactions{pending, queued} {
+		for _, txs := range content {
+			if hasDepositInChildChainTx(txs, txHash) {
+				return true, false, nil
+			}
+		}
+	}
+	return false, false, nil
+}
+
+// GetWithdrawStatusInMainChain check if the withdraw has been relayed (tx3 proof received) or completed in the main chain
+func (cch *CrossChainHelper) GetWithdrawStatusInMainChain(chainId string, from common.Address, txHash common.Hash) (relayed, completed bool) {
+	ethereum := MustGetEthereumFromNode(chainMgr.mainChain.EthNode)
+	if stateDB, err := ethereum.BlockChain().State(); err == nil && stateDB.HasTX3(from, txHash) {
+		return true, true
+	}
+	return cch.GetTX3(chainId, txHash) != nil, false
+}
+
+// GetExpiredCrossChainTransfers returns the settlements of the expired deposits, the deposit delivered to the
+// child chain is marked as completed, otherwise it's refunded. The deposit to the child chain which is not
+// running on this node is skipped, as it can't be verified
+func (cch *CrossChainHelper) GetExpiredCrossChainTransfers() []*pabi.SettleCrossChainTransferArgs {
+	timeout := cch.GetCrossChainTransferTimeout()
+	if timeout == 0 {
+		return nil
+	}
+
+	ethereum := MustGetEthereumFromNode(chainMgr.mainChain.EthNode)
+	stateDB, err := ethereum.BlockChain().State()
+	if err != nil {
+		return nil
+	}
+	height := ethereum.BlockChain().CurrentBlock().NumberU64()
+
+	cch.settleMtx.Lock()
+	defer cch.settleMtx.Unlock()
+	if cch.settling == nil {
+		cch.settling = make(map[common.Hash]uint64)
+	}
+
+	var result []*pabi.SettleCrossChainTransferArgs
+	for _, pt := range core.GetPendingTransfers(cch.chainInfoDB) {
+		if status, _ := stateDB.GetCrossChainTransfer(pt.TxHash); status != state.CrossChainTransferLocked {
+			core.RemovePendingTransfer(cch.chainInfoDB, pt.TxHash)
+			delete(cch.settling, pt.TxHash)
+			continue
+		}
+
+		if height < pt.LockHeight+timeout {
+			continue
+		}
+
+		// Don't send the settlement again if it's still on the way
+		if sent, ok := cch.settling[pt.TxHash]; ok && height < sent+settleResendBlocks {
+			continue
+		}
+
+		_, minted, err := cch.GetDepositStatusInChildChain(pt.ChainId, pt.From, pt.TxHash)
+		if err != nil {
+			log.Debugf("GetExpiredCrossChainTransfers - skip tx %x, %v", pt.TxHash, err)
+			continue
+		}
+
+		cch.settling[pt.TxHash] = height
+		result = append(result, &pabi.SettleCrossChainTransferArgs{TxHash: pt.TxHash, Refund: !minted})
+	}
+	return result
+}
+
+// hasDepositInChildChainTx check if there is a DepositInChildChain tx consuming the deposit in the txs
+func hasDepositInChildChainTx(txs types.Transactions, txHash common.Hash) bool {
+	for _, tx := range txs {
+		data := tx.Data()
+		if !pabi.IsPChainContractAddr(tx.To()) || len(data) < 4 {
+			continue
+		}
+		if function, err := pabi.FunctionTypeFromId(data[:4]); err != nil || function != pabi.DepositInChildChain {
+			continue
+		}
+
+		var args pabi.DepositInChildChainArgs
+		if err := pabi.ChainABI.UnpackMethodInputs(&args, pabi.DepositInChildChain.String(), data[4:]); err == nil && args.TxHash == txHash {
+			return true
+		}
+	}
+	return false
+}
+
 func (cch *CrossChainHelper) ValidateTX3ProofData(proofData *types.TX3ProofData) error {
 	log.Debug("ValidateTX3ProofData - start")
 
@@ -541,14 +1320,6 @@ func (cch *CrossChainHelper) ValidateTX3ProofData(proofData *types.TX3ProofData)
 
 func (cch *CrossChainHelper) ValidateTX4WithInMemTX3ProofData(tx4 *types.Transaction, tx3ProofData *types.TX3ProofData) error {
 	// TX4
-	signer := types.NewEIP155Signer(tx4.ChainId())
-	from, err := types.Sender(signer, tx4)
-	if err != nil {
-		return core.ErrInvalidSender
-	}
-
-	var args pabi.WithdrawFromMainChainArgs
-
 	if !pabi.IsPChainContractAddr(tx4.To()) {
 		return errors.New("invalid TX4: wrong To()")
 	}
@@ -559,14 +1330,10 @@ func (cch *CrossChainHelper) ValidateTX4WithInMemTX3ProofData(tx4 *types.Transac
 		return err
 	}
 
-	if function != pabi.WithdrawFromMainChain {
+	if !function.IsWithdrawFromMainChain() {
 		return errors.New("invalid TX4: wrong function")
 	}
 
-	if err := pabi.ChainABI.UnpackMethodInputs(&args, pabi.WithdrawFromMainChain.String(), data[4:]); err != nil {
-		return err
-	}
-
 	// TX3
 	header := tx3ProofData.Header
 	if err != nil {
@@ -585,21 +1352,9 @@ func (cch *CrossChainHelper) ValidateTX4WithInMemTX3ProofData(tx4 *types.Transac
 		return err
 	}
 
-	signer2 := types.NewEIP155Signer(tx3.ChainId())
-	tx3From, err := types.Sender(signer2, &tx3)
-	if err != nil {
-		return core.ErrInvalidSender
-	}
-
-	var tx3Args pabi.WithdrawFromChildChainArgs
-	tx3Data := tx3.Data()
-	if err := pabi.ChainABI.UnpackMethodInputs(&tx3Args, pabi.WithdrawFromChildChain.String(), tx3Data[4:]); err != nil {
-		return err
-	}
-
 	// Does TX3 & TX4 Match
-	if from != tx3From || args.ChainId != tx3Args.ChainId || args.Amount.Cmp(tx3.Value()) != 0 {
-		return errors.New("params are not consistent with tx in child chain")
+	if err := core.ValidateTX4WithTX3(tx4, &tx3); err != nil {
+		return fmt.Errorf("params are not consistent with tx in child chain: %v", err)
 	}
 
 	return nil
@@ -0,0 +1,462 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/tendermint/epoch"
+	tdmTypes "github.com/ethereum/go-ethereum/consensus/tendermint/types"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	pabi "github.com/pchain/abi"
+	cmn "github.com/tendermint/go-common"
+	"github.com/tendermint/go-crypto"
+	dbm "github.com/tendermint/go-db"
+)
+
+var (
+	decommissionOwner = common.HexToAddress("0xa0")
+	decommissionVals  = []common.Address{common.HexToAddress("0xa1"), common.HexToAddress("0xa2"), common.HexToAddress("0xa3"), common.HexToAddress("0xa4")}
+)
+
+type proofList [][]byte
+
+func (n *proofList) Put(key []byte, value []byte) error {
+	*n = append(*n, value)
+	return nil
+}
+
+func newDecommissionHelper(t *testing.T) *CrossChainHelper {
+	ci := &core.ChainInfo{CoreChainInfo: core.CoreChainInfo{Owner: decommissionOwner, ChainId: "child_0"}}
+	for _, val := range decommissionVals {
+		ci.JoinedValidators = append(ci.JoinedValidators, core.JoinedValidator{Address: val, DepositAmount: big.NewInt(100)})
+	}
+	cch := &CrossChainHelper{chainInfoDB: dbm.NewMemDB()}
+	if err := core.SaveChainInfo(cch.chainInfoDB, ci); err != nil {
+		t.Fatal(err)
+	}
+	return cch
+}
+
+func decommission(cch *CrossChainHelper, from common.Address, finalBlock uint64) error {
+	if err := cch.CanDecommissionChildChain(from, "child_0", new(big.Int).SetUint64(finalBlock)); err != nil {
+		return err
+	}
+	return cch.DecommissionChildChain(from, "child_0", finalBlock)
+}
+
+func TestDecommissionChildChainApproval(t *testing.T) {
+	cch := newDecommissionHelper(t)
+
+	if err := decommission(cch, decommissionVals[0], 100); err != core.ErrNotOwner {
+		t.Fatalf("proposed by validator: %v, want %v", err, core.ErrNotOwner)
+	}
+	if err := decommission(cch, decommissionOwner, 100); err != nil {
+		t.Fatalf("failed to propose: %v", err)
+	}
+	if di := core.GetDecommissionInfo(cch.chainInfoDB, "child_0"); di == nil || di.IsApproved() {
+		t.Fatalf("decommission %+v, want proposed only", di)
+	}
+
+	if err := decommission(cch, decommissionOwner, 100); err != core.ErrNotChildChainValidator {
+		t.Errorf("approved by owner: %v, want %v", err, core.ErrNotChildChainValidator)
+	}
+	if err := decommission(cch, decommissionVals[0], 101); err == nil {
+		t.Error("approved with another final block")
+	}
+	for i, val := range decommissionVals[:3] {
+		if di := core.GetDecommissionInfo(cch.chainInfoDB, "child_0"); di.IsApproved() {
+			t.Fatalf("approved by %d of 4 validators", i)
+		}
+		if err := decommission(cch, val, 100); err != nil {
+			t.Fatalf("validator %d failed to approve: %v", i, err)
+		}
+		if err := decommission(cch, val, 100); i < 2 && err != core.ErrDecommissionApproved {
+			t.Errorf("validator %d approved twice: %v", i, err)
+		}
+	}
+
+	di := core.GetDecommissionInfo(cch.chainInfoDB, "child_0")
+	if di.Status != core.DecommissionApproved || len(di.Approvals) != 3 {
+		t.Fatalf("decommission %+v, want approved by 3 of 4 validators", di)
+	}
+	if err := decommission(cch, decommissionVals[3], 100); err != core.ErrChildChainDecommissioned {
+		t.Errorf("approved after approval: %v, want %v", err, core.ErrChildChainDecommissioned)
+	}
+}
+
+func TestDecommissionFork(t *testing.T) {
+	config := *params.TestChainConfig
+	config.ChildChainDecommissionBlock = big.NewInt(10)
+	for _, function := range []pabi.FunctionType{pabi.DecommissionChildChain, pabi.ClaimChildChainAsset} {
+		if core.IsChainFunctionEnabled(&config, function, big.NewInt(9)) {
+			t.Errorf("%v enabled before the fork", function)
+		}
+		if !core.IsChainFunctionEnabled(&config, function, big.NewInt(10)) {
+			t.Errorf("%v not enabled at the fork", function)
+		}
+	}
+	if !core.IsChainFunctionEnabled(&config, pabi.DepositInChildChain, big.NewInt(9)) {
+		t.Error("existing function not enabled before the fork")
+	}
+}
+
+// newClaimingChain commits the final state of the child chain and starts its claims period
+func newClaimingChain(t *testing.T, balances map[common.Address]int64) (*CrossChainHelper, *state.StateDB) {
+	cch := newDecommissionHelper(t)
+
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	for addr, balance := range balances {
+		statedb.AddBalance(addr, big.NewInt(balance))
+	}
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	core.SaveDecommissionInfo(cch.chainInfoDB, &core.DecommissionInfo{
+		ChainId:        "child_0",
+		FinalBlock:     100,
+		FinalStateRoot: root,
+		ClaimsEnd:      1000,
+		Status:         core.DecommissionClaiming,
+	})
+	return cch, statedb
+}
+
+func assetProof(t *testing.T, statedb *state.StateDB, addr common.Address) []byte {
+	tr, err := statedb.Database().OpenTrie(statedb.IntermediateRoot(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var proof proofList
+	if err := tr.Prove(ethcrypto.Keccak256(addr.Bytes()), 0, &proof); err != nil {
+		t.Fatal(err)
+	}
+	enc, _ := rlp.EncodeToBytes([][]byte(proof))
+	return enc
+}
+
+func TestClaimChildChainAsset(t *testing.T) {
+	holder, other := common.HexToAddress("0xb1"), common.HexToAddress("0xb2")
+	cch, statedb := newClaimingChain(t, map[common.Address]int64{holder: 70, other: 30})
+
+	proof := assetProof(t, statedb, holder)
+	amount, err := cch.ValidateChildChainAssetClaim(holder, "child_0", proof)
+	if err != nil {
+		t.Fatalf("failed to validate the claim: %v", err)
+	}
+	if amount.Cmp(big.NewInt(70)) != 0 {
+		t.Fatalf("claimed %v, want 70", amount)
+	}
+	if _, err := cch.ValidateChildChainAssetClaim(other, "child_0", proof); err == nil {
+		t.Error("claimed with the proof of another account")
+	}
+
+	cch.ClaimChildChainAsset("child_0", holder, amount)
+	if _, err := cch.ValidateChildChainAssetClaim(holder, "child_0", proof); err != core.ErrAssetClaimed {
+		t.Errorf("claimed twice: %v, want %v", err, core.ErrAssetClaimed)
+	}
+	if claims := core.GetChildChainAssetClaims(cch.chainInfoDB, "child_0"); len(claims) != 1 || claims[0].Account != holder {
+		t.Errorf("claims %v, want the claim of the holder only", claims)
+	}
+}
+
+func TestReleaseChildChainClaims(t *testing.T) {
+	holder, other := common.HexToAddress("0xb1"), common.HexToAddress("0xb2")
+	tests := []struct {
+		escrow           int64
+		wantHolder, want int64
+	}{
+		{escrow: 1000, wantHolder: 100, want: 200}, // all the claims are paid
+		{escrow: 150, wantHolder: 50, want: 100},   // over claimed, shared out in proportion
+		{escrow: 0, wantHolder: 0, want: 0},
+	}
+	for i, test := range tests {
+		cch, _ := newClaimingChain(t, map[common.Address]int64{holder: 100, other: 200})
+		cch.ClaimChildChainAsset("child_0", holder, big.NewInt(100))
+		cch.ClaimChildChainAsset("child_0", other, big.NewInt(200))
+
+		db, _ := ethdb.NewMemDatabase()
+		mainState, _ := state.New(common.Hash{}, state.NewDatabase(db))
+		mainState.AddChainBalance(decommissionOwner, big.NewInt(test.escrow+400))
+
+		if released := cch.ReadyForReleaseChildChain(big.NewInt(999), mainState); len(released) != 0 {
+			t.Fatalf("test %d: released %v before the end of the claims period", i, released)
+		}
+		if released := cch.ReadyForReleaseChildChain(big.NewInt(1000), mainState); len(released) != 1 {
+			t.Fatalf("test %d: released %v, want the child chain", i, released)
+		}
+		if b := mainState.GetBalance(holder); b.Cmp(big.NewInt(test.wantHolder)) != 0 {
+			t.Errorf("test %d: holder paid %v, want %v", i, b, test.wantHolder)
+		}
+		if b := mainState.GetBalance(other); b.Cmp(big.NewInt(test.want)) != 0 {
+			t.Errorf("test %d: other paid %v, want %v", i, b, test.want)
+		}
+		for _, val := range decommissionVals {
+			if b := mainState.GetBalance(val); b.Cmp(big.NewInt(100)) != 0 {
+				t.Errorf("test %d: validator deposit %v released, want 100", i, b)
+			}
+		}
+	}
+}
+
+func TestValidatorExitFork(t *testing.T) {
+	config := *params.TestChainConfig
+	config.ValidatorExitBlock = big.NewInt(10)
+	for _, function := range []pabi.FunctionType{pabi.DepositTopUp, pabi.VoluntaryExit} {
+		if core.IsChainFunctionEnabled(&config, function, big.NewInt(9)) {
+			t.Errorf("%v enabled before the fork", function)
+		}
+		if !core.IsChainFunctionEnabled(&config, function, big.NewInt(10)) {
+			t.Errorf("%v not enabled at the fork", function)
+		}
+	}
+}
+
+// newVotedEpoch makes the epoch 1 with the validator revealed its vote for the epoch 2
+func newVotedEpoch(val common.Address, amount int64) *epoch.Epoch {
+	ep := epoch.MakeOneEpoch(dbm.NewMemDB(), &tdmTypes.OneEpochDoc{Number: 1, RewardPerBlock: big.NewInt(0), StartBlock: 1, EndBlock: 100}, log.New())
+	next := epoch.MakeOneEpoch(nil, &tdmTypes.OneEpochDoc{Number: 2, RewardPerBlock: big.NewInt(0), StartBlock: 101, EndBlock: 200}, log.New())
+	ep.SetNextEpoch(next)
+
+	voteSet := epoch.NewEpochValidatorVoteSet()
+	voteSet.StoreVote(&epoch.EpochValidatorVote{Address: val, PubKey: crypto.BLSPubKey{1}, Amount: big.NewInt(amount), Salt: "salt"})
+	next.SetEpochValidatorVoteSet(voteSet)
+	return ep
+}
+
+func TestDepositTopUp(t *testing.T) {
+	cch := &CrossChainHelper{}
+	val, other := decommissionVals[0], decommissionVals[1]
+	ep := newVotedEpoch(val, 100)
+
+	if err := cch.DepositTopUp(ep, val, big.NewInt(50)); err != nil {
+		t.Fatalf("failed to top up: %v", err)
+	}
+	// The revealed vote of the validator is topped up and saved
+	vote, _ := epoch.LoadEpochVoteSet(ep.GetDB(), 2).GetVoteByAddress(val)
+	if vote == nil || vote.Amount.Cmp(big.NewInt(150)) != 0 {
+		t.Errorf("vote %v after the top up, want 150", vote)
+	}
+	// Without a vote, the top up is counted at the epoch switch from the deposit balance
+	if err := cch.DepositTopUp(ep, other, big.NewInt(50)); err != nil {
+		t.Fatalf("failed to top up: %v", err)
+	}
+	if _, exist := ep.GetNextEpoch().GetEpochValidatorVoteSet().GetVoteByAddress(other); exist {
+		t.Error("vote added by the top up")
+	}
+}
+
+func TestVoluntaryExit(t *testing.T) {
+	cch := &CrossChainHelper{}
+	val := decommissionVals[0]
+	ep := newVotedEpoch(val, 100)
+
+	if err := cch.VoluntaryExit(ep, val); err != nil {
+		t.Fatalf("failed to exit: %v", err)
+	}
+	if err := cch.VoluntaryExit(ep, val); err != nil {
+		t.Fatalf("failed to exit twice: %v", err)
+	}
+	exitSet := epoch.LoadEpochValidatorExitSet(ep.GetDB(), 2)
+	if !exitSet.HasAddress(val) || len(exitSet.Exits) != 1 {
+		t.Errorf("exit set %v, want the validator exit the epoch 2 once", exitSet.Exits)
+	}
+	if epoch.LoadEpochValidatorExitSet(ep.GetDB(), 1).HasAddress(val) {
+		t.Error("validator exit the current epoch")
+	}
+}
+
+func TestSetChildChainStateRent(t *testing.T) {
+	cch := &CrossChainHelper{chainInfoDB: dbm.NewMemDB()}
+	core.CreatePendingChildChainData(cch.chainInfoDB, &core.CoreChainInfo{
+		Owner:      decommissionOwner,
+		ChainId:    "child_1",
+		StartBlock: big.NewInt(10),
+		EndBlock:   big.NewInt(20),
+	})
+
+	tests := []struct {
+		from                              common.Address
+		chainId                           string
+		period, accountRent, codeByteRent int64
+		ok                                bool
+	}{
+		{decommissionOwner, "child_1", 100, 10, 1, true},
+		{decommissionOwner, "child_1", 100, 0, 0, true},
+		{decommissionVals[0], "child_1", 100, 10, 1, false}, // not the owner
+		{decommissionOwner, "child_2", 100, 10, 1, false},   // not pending
+		{decommissionOwner, "child_1", 0, 10, 1, false},
+		{decommissionOwner, "child_1", 100, -1, 1, false},
+		{decommissionOwner, "child_1", 100, 10, -1, false},
+	}
+	for i, test := range tests {
+		err := cch.CanSetChildChainStateRent(test.from, test.chainId, big.NewInt(test.period), big.NewInt(test.accountRent), big.NewInt(test.codeByteRent))
+		if (err == nil) != test.ok {
+			t.Errorf("test %d: error %v, want ok %v", i, err, test.ok)
+		}
+	}
+
+	if err := cch.SetChildChainStateRent(decommissionOwner, "child_1", 100, big.NewInt(10), big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	rent := core.GetChildChainStateRent(cch.chainInfoDB, "child_1")
+	if rent == nil || rent.Period != 100 || rent.AccountRent.Cmp(big.NewInt(10)) != 0 || rent.CodeByteRent.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("state rent %+v, want the saved config", rent)
+	}
+	if core.GetChildChainStateRent(cch.chainInfoDB, "child_2") != nil {
+		t.Error("state rent enabled for another chain")
+	}
+}
+
+func TestPendingCrossChainTransfers(t *testing.T) {
+	cch := &CrossChainHelper{chainInfoDB: dbm.NewMemDB()}
+	tx1, tx2 := common.HexToHash("0x01"), common.HexToHash("0x02")
+
+	cch.LockCrossChainTransfer(tx1, "child_0", decommissionOwner, 10)
+	cch.LockCrossChainTransfer(tx2, "child_0", decommissionOwner, 11)
+	cch.LockCrossChainTransfer(tx1, "child_0", decommissionOwner, 12)
+	pts := core.GetPendingTransfers(cch.chainInfoDB)
+	if len(pts) != 2 || pts[0].TxHash != tx1 || pts[0].LockHeight != 10 || pts[1].TxHash != tx2 {
+		t.Fatalf("pending transfers %v, want tx1 locked at 10 and tx2", pts)
+	}
+
+	cch.settling = map[common.Hash]uint64{tx1: 20}
+	cch.SettleCrossChainTransfer(tx1)
+	if pts := core.GetPendingTransfers(cch.chainInfoDB); len(pts) != 1 || pts[0].TxHash != tx2 {
+		t.Errorf("pending transfers %v after the settlement, want tx2 only", pts)
+	}
+	if _, ok := cch.settling[tx1]; ok {
+		t.Error("settled transfer still being settled")
+	}
+}
+
+func TestHasDepositInChildChainTx(t *testing.T) {
+	txHash := common.HexToHash("0x01")
+	deposit := func(to common.Address, hash common.Hash) *types.Transaction {
+		data, err := pabi.ChainABI.Pack(pabi.DepositInChildChain.String(), "child_0", hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return types.NewTransaction(0, to, big.NewInt(0), 0, big.NewInt(0), data)
+	}
+	other := common.HexToAddress("0x02")
+
+	tests := []struct {
+		txs  types.Transactions
+		want bool
+	}{
+		{types.Transactions{deposit(pabi.ChainContractMagicAddr, txHash)}, true},
+		{types.Transactions{deposit(pabi.ChainContractMagicAddr, other.Hash()), deposit(pabi.ChainContractMagicAddr, txHash)}, true},
+		{types.Transactions{deposit(pabi.ChainContractMagicAddr, other.Hash())}, false},
+		{types.Transactions{deposit(other, txHash)}, false},
+		{types.Transactions{types.NewTransaction(0, pabi.ChainContractMagicAddr, big.NewInt(0), 0, big.NewInt(0), nil)}, false},
+		{nil, false},
+	}
+	for i, test := range tests {
+		if has := hasDepositInChildChainTx(test.txs, txHash); has != test.want {
+			t.Errorf("test %d: has deposit %v, want %v", i, has, test.want)
+		}
+	}
+}
+
+func TestGetChildChainValidators(t *testing.T) {
+	cch := newDecommissionHelper(t)
+	core.CreatePendingChildChainData(cch.chainInfoDB, &core.CoreChainInfo{
+		ChainId:          "child_1",
+		StartBlock:       big.NewInt(10),
+		EndBlock:         big.NewInt(20),
+		JoinedValidators: []core.JoinedValidator{{Address: decommissionVals[0], PubKey: crypto.BLSPubKey{1}, DepositAmount: big.NewInt(100)}},
+	})
+
+	// The joined validators of the launched chain without epoch
+	if vals := core.GetChildChainValidators(cch.chainInfoDB, "child_0"); len(vals) != len(decommissionVals) || vals[3].Address != decommissionVals[3] {
+		t.Errorf("validators %v, want the joined validators", vals)
+	}
+	// The joined validators of the pending chain
+	vals := core.GetChildChainValidators(cch.chainInfoDB, "child_1")
+	if len(vals) != 1 || vals[0].Address != decommissionVals[0] || !vals[0].PubKey.Equals(crypto.BLSPubKey{1}) {
+		t.Errorf("validators %v, want the pending joined validator", vals)
+	}
+	if vals := core.GetChildChainValidators(cch.chainInfoDB, "child_2"); vals != nil {
+		t.Errorf("validators %v of the unknown chain, want nil", vals)
+	}
+}
+
+func TestLaunchAttendance(t *testing.T) {
+	la := &core.LaunchAttendance{ChainId: "child_0", Validators: decommissionVals, Attended: make([]bool, len(decommissionVals))}
+
+	// The signers are taken from the bit array of the commit
+	var vals []*tdmTypes.Validator
+	for i, val := range decommissionVals[:3] {
+		vals = append(vals, tdmTypes.NewValidator(val.Bytes(), crypto.BLSPubKey{byte(i + 1)}, big.NewInt(100)))
+	}
+	valSet := tdmTypes.NewValidatorSet(vals)
+	bitArray := cmn.NewBitArray(uint64(valSet.Size()))
+	bitArray.SetIndex(0, true)
+	bitArray.SetIndex(2, true)
+	signers := valSet.Signers(bitArray)
+	if len(signers) != 2 {
+		t.Fatalf("signers %x, want 2", signers)
+	}
+	if valSet.Signers(cmn.NewBitArray(2)) != nil {
+		t.Error("signers returned with the bit array of another validator set")
+	}
+
+	if !la.HasNewAttendance(signers) || !la.MarkAttended(signers) {
+		t.Fatal("signers not marked attended")
+	}
+	if la.HasNewAttendance(signers) || la.MarkAttended(signers) {
+		t.Error("signers marked attended twice")
+	}
+	if absent := la.Absent(); len(absent) != 2 || containsAddress(absent, signers[0]) || containsAddress(absent, signers[1]) {
+		t.Errorf("absent %x, want the validators not signed", absent)
+	}
+}
+
+func TestPenalizeAbsentValidators(t *testing.T) {
+	cch := newDecommissionHelper(t)
+	attended := []bool{true, true, false, false}
+	core.SaveLaunchAttendance(cch.chainInfoDB, &core.LaunchAttendance{ChainId: "child_0", GraceEnd: 100, Validators: decommissionVals, Attended: attended})
+
+	db, _ := ethdb.NewMemDatabase()
+	mainState, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	mainState.AddChainBalance(decommissionOwner, big.NewInt(400))
+
+	if penalized := cch.ReadyForPenalizeAbsentValidators(big.NewInt(99), mainState); len(penalized) != 0 {
+		t.Fatalf("penalized %v in the grace period", penalized)
+	}
+	penalized := cch.ReadyForPenalizeAbsentValidators(big.NewInt(100), mainState)
+	if len(penalized) != 1 || penalized[0] != "child_0" {
+		t.Fatalf("penalized %v, want the child chain", penalized)
+	}
+	// The absent validators are refunded the deposit less the slash, the slash is burnt
+	for i, val := range decommissionVals {
+		want := int64(0)
+		if !attended[i] {
+			want = 100 - 100*core.LaunchAbsenceSlashPercent/100
+		}
+		if b := mainState.GetBalance(val); b.Cmp(big.NewInt(want)) != 0 {
+			t.Errorf("validator %d refunded %v, want %v", i, b, want)
+		}
+	}
+	if b := mainState.GetChainBalance(decommissionOwner); b.Cmp(big.NewInt(200)) != 0 {
+		t.Errorf("chain balance %v, want the deposits of the attended validators", b)
+	}
+
+	cch.PenalizeAbsentValidators(penalized)
+	if ci := core.GetChainInfo(cch.chainInfoDB, "child_0"); len(ci.JoinedValidators) != 2 || ci.JoinedValidators[1].Address != decommissionVals[1] {
+		t.Errorf("joined validators %v, want the attended validators", ci.JoinedValidators)
+	}
+	if penalized := cch.ReadyForPenalizeAbsentValidators(big.NewInt(101), mainState); len(penalized) != 0 {
+		t.Errorf("penalized %v again", penalized)
+	}
+}
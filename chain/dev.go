@@ -0,0 +1,115 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/tendermint/types"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	// AllRPCModules are all the namespaces of pchain, served in developer mode and by the localnet nodes
+	AllRPCModules = "admin,chain,debug,del,eth,miner,net,personal,pi,tdm,txpool,web3"
+
+	// Locked deposit of the developer validator
+	devValidatorStake = "100000pi"
+)
+
+// devConfigTmpl is the tendermint config of the developer chain, the databases are kept in memory
+// and the consensus steps are shortened, so that the block is committed as soon as the miner seals it
+var devConfigTmpl = `# This is a TOML config file.
+# For more information, see https://github.com/toml-lang/toml
+moniker = "dev"
+seeds = ""
+db_backend = "memdb"
+timeout_wait_for_miner_block = 100
+timeout_propose = 100
+timeout_propose_delta = 0
+timeout_prevote = 100
+timeout_prevote_delta = 0
+timeout_precommit = 100
+timeout_precommit_delta = 0
+timeout_commit = __TIMEOUT_COMMIT__
+skip_timeout_commit = __SKIP_TIMEOUT_COMMIT__
+`
+
+// InitDevChain initialises the ephemeral developer chain in the data directory. The chain has a single validator,
+// the validator and the accounts of the dev.accounts flag are pre-funded with the balance of the dev.balance flag
+func InitDevChain(ctx *cli.Context, chainId string) error {
+
+	datadir := ctx.GlobalString(utils.DataDirFlag.Name)
+
+	// Mine only on the new transactions, or commit the block every period
+	period := ctx.GlobalInt(utils.DeveloperPeriodFlag.Name)
+	tmConfig := strings.NewReplacer(
+		"__TIMEOUT_COMMIT__", strconv.Itoa(period*1000),
+		"__SKIP_TIMEOUT_COMMIT__", strconv.FormatBool(period == 0),
+	).Replace(devConfigTmpl)
+	if err := ioutil.WriteFile(filepath.Join(datadir, "config.toml"), []byte(tmConfig), 0644); err != nil {
+		return err
+	}
+	config := GetTendermintConfig(chainId, ctx)
+	Config = config
+
+	balance, err := common.ParseQuantity(ctx.GlobalString(utils.DeveloperBalanceFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid developer balance: %v", err)
+	}
+	stake, err := common.ParseQuantity(devValidatorStake)
+	if err != nil {
+		return err
+	}
+
+	var coreGenesis = newMainChainGenesis()
+	for _, account := range strings.Split(ctx.GlobalString(utils.DeveloperAccountsFlag.Name), ",") {
+		account = strings.TrimSpace(account)
+		if account == "" {
+			continue
+		}
+		address, err := common.ParseAddress(account)
+		if err != nil {
+			return fmt.Errorf("invalid developer account: %v", account)
+		}
+		coreGenesis.Alloc[address] = core.GenesisAccount{
+			Balance: balance,
+			Amount:  common.Big0,
+		}
+	}
+
+	validator := createPriValidators(config, 1)[0]
+	coreGenesis.Alloc[validator.Address] = core.GenesisAccount{
+		Balance: balance,
+		Amount:  stake,
+	}
+
+	contents, err := json.MarshalIndent(coreGenesis, "", "\t")
+	if err != nil {
+		return err
+	}
+	ethGenesisPath := config.GetString("eth_genesis_file")
+	if err := ioutil.WriteFile(ethGenesisPath, contents, 0644); err != nil {
+		return err
+	}
+
+	validators := []types.GenesisValidator{{
+		EthAccount: validator.Address,
+		PubKey:     validator.PubKey,
+		Amount:     stake,
+	}}
+	if err := init_em_files(config, chainId, ethGenesisPath, validators); err != nil {
+		return err
+	}
+
+	log.Info("Developer chain initialised", "datadir", datadir, "validator", validator.Address, "password", DefaultAccountPassword,
+		"accounts", len(coreGenesis.Alloc), "balance", balance)
+	return nil
+}
@@ -1,6 +1,7 @@
 package chain
 
 import (
+	"fmt"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/pchain/abi"
 	"os"
@@ -12,15 +13,14 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/ethdb"
 
+	"bls"
 	"encoding/json"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/cmd/geth"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/consensus/tendermint/types"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/pkg/errors"
-	cmn "github.com/tendermint/go-common"
 	cfg "github.com/tendermint/go-config"
 	"io/ioutil"
 	"math/big"
@@ -31,8 +31,8 @@ import (
 )
 
 const (
-	POSReward  = "315000000000000000000000000"
-	LockReward = "11500000000000000000000000" // 11.5m
+	POSReward  = "315000000pi"
+	LockReward = "11500000pi" // 11.5m
 
 	DefaultAccountPassword = "pchain"
 )
@@ -51,7 +51,7 @@ func (invalid InvalidArgs) Error() string {
 }
 
 func parseBalaceAmount(s string) ([]*BalaceAmount, error) {
-	r, _ := regexp.Compile("\\{[\\ \\t]*\\d+(\\.\\d+)?[\\ \\t]*\\,[\\ \\t]*\\d+(\\.\\d+)?[\\ \\t]*\\}")
+	r, _ := regexp.Compile("\\{[\\ \\t]*\\d+(\\.\\d+)?[a-zA-Z]*[\\ \\t]*\\,[\\ \\t]*\\d+(\\.\\d+)?[a-zA-Z]*[\\ \\t]*\\}")
 	parse_strs := r.FindAllString(s, -1)
 	if len(parse_strs) == 0 {
 		return nil, InvalidArgs{s}
@@ -87,15 +87,44 @@ func InitCmd(ctx *cli.Context) error {
 	return init_cmd(ctx, GetTendermintConfig(chainId, ctx), chainId, ethGenesisPath)
 }
 
-func init_cmd(ctx *cli.Context, config cfg.Config, chainId string, ethGenesisPath string) error {
+// GenesisHashCmd print the hashes of the canonical json of the genesis documents,
+// operators can compare the hash to verify they are using the identical chain setup
+func GenesisHashCmd(ctx *cli.Context) error {
 
-	init_eth_blockchain(chainId, ethGenesisPath, ctx)
+	genesisPath := ctx.Args().First()
+	if len(genesisPath) == 0 {
+		utils.Fatalf("must supply path to genesis JSON file")
+	}
 
-	init_em_files(config, chainId, ethGenesisPath, nil)
+	contents, err := ioutil.ReadFile(genesisPath)
+	if err != nil {
+		utils.Fatalf("failed to read genesis file: %v", err)
+		return err
+	}
 
+	genDoc, err := types.GenesisDocFromJSON(contents)
+	if err != nil {
+		utils.Fatalf("invalid genesis file: %v", err)
+		return err
+	}
+
+	hashes, err := genDoc.ComputeDocHashes()
+	if err != nil {
+		utils.Fatalf("failed to hash genesis file: %v", err)
+		return err
+	}
+
+	fmt.Printf("reward_scheme: %x\ncurrent_epoch: %x\ngenesis:       %x\n", hashes.RewardScheme, hashes.CurrentEpoch, hashes.Genesis)
 	return nil
 }
 
+func init_cmd(ctx *cli.Context, config cfg.Config, chainId string, ethGenesisPath string) error {
+
+	init_eth_blockchain(chainId, ethGenesisPath, ctx)
+
+	return init_em_files(config, chainId, ethGenesisPath, nil)
+}
+
 func InitEthGenesis(ctx *cli.Context) error {
 	log.Info("this is init_eth_genesis")
 	args := ctx.Args()
@@ -122,22 +151,21 @@ func init_eth_genesis(config cfg.Config, balStr string) error {
 
 	validators := createPriValidators(config, len(balanceAmounts))
 
-	var coreGenesis = core.Genesis{
-		Config:     params.MainnetChainConfig,
-		Nonce:      0xdeadbeefdeadbeef,
-		Timestamp:  0x0,
-		ParentHash: common.Hash{},
-		ExtraData:  []byte("0x0"),
-		GasLimit:   0x8000000,
-		Difficulty: new(big.Int).SetUint64(0x400),
-		Mixhash:    common.Hash{},
-		Coinbase:   common.Address{},
-		Alloc:      core.GenesisAlloc{},
-	}
+	var coreGenesis = newMainChainGenesis()
 	for i, validator := range validators {
+		balance, err := common.ParseQuantity(balanceAmounts[i].balance)
+		if err != nil {
+			utils.Fatalf("invalid balance: %v", err)
+			return err
+		}
+		amount, err := common.ParseQuantity(balanceAmounts[i].amount)
+		if err != nil {
+			utils.Fatalf("invalid amount: %v", err)
+			return err
+		}
 		coreGenesis.Alloc[validator.Address] = core.GenesisAccount{
-			Balance: math.MustParseBig256(balanceAmounts[i].balance),
-			Amount:  math.MustParseBig256(balanceAmounts[i].amount),
+			Balance: balance,
+			Amount:  amount,
 		}
 	}
 
@@ -154,12 +182,33 @@ func init_eth_genesis(config cfg.Config, balStr string) error {
 	return nil
 }
 
+// newMainChainGenesis returns the main chain genesis without any account allocated
+func newMainChainGenesis() core.Genesis {
+	return core.Genesis{
+		Config:     params.MainnetChainConfig,
+		Nonce:      0xdeadbeefdeadbeef,
+		Timestamp:  0x0,
+		ParentHash: common.Hash{},
+		ExtraData:  []byte("0x0"),
+		GasLimit:   0x8000000,
+		Difficulty: new(big.Int).SetUint64(0x400),
+		Mixhash:    common.Hash{},
+		Coinbase:   common.Address{},
+		Alloc:      core.GenesisAlloc{},
+	}
+}
+
 func init_eth_blockchain(chainId string, ethGenesisPath string, ctx *cli.Context) {
+	init_eth_blockchain_in(utils.MakeDataDir(ctx), chainId, ethGenesisPath)
+}
+
+// init_eth_blockchain_in writes the genesis block of the chain into the chain database under the data directory
+func init_eth_blockchain_in(datadir string, chainId string, ethGenesisPath string) {
 
-	dbPath := filepath.Join(utils.MakeDataDir(ctx), chainId, "geth/chaindata")
+	dbPath := filepath.Join(datadir, chainId, "geth/chaindata")
 	log.Infof("init_eth_blockchain 0 with dbPath: %s", dbPath)
 
-	chainDb, err := ethdb.NewLDBDatabase(filepath.Join(utils.MakeDataDir(ctx), chainId, gethmain.ClientIdentifier, "chaindata"), 0, 0)
+	chainDb, err := ethdb.NewLDBDatabase(filepath.Join(datadir, chainId, gethmain.ClientIdentifier, "chaindata"), 0, 0)
 	if err != nil {
 		utils.Fatalf("could not open database: %v", err)
 	}
@@ -208,7 +257,9 @@ func init_em_files(config cfg.Config, chainId string, genesisPath string, valida
 			return nil
 		}
 		// Now load the priv_validator_file
-		privValidator = types.LoadPrivValidator(privValPath)
+		if privValidator, err = types.LoadPrivValidator(privValPath); err != nil {
+			return err
+		}
 	}
 
 	// Create the Genesis Doc
@@ -225,9 +276,15 @@ func createGenesisDoc(config cfg.Config, chainId string, coreGenesis *core.Genes
 
 		var rewardScheme types.RewardSchemeDoc
 		if chainId == MainChain || chainId == TestnetChain {
-			posReward, _ := new(big.Int).SetString(POSReward, 10)
-			LockReward, _ := new(big.Int).SetString(LockReward, 10)
-			totalReward := new(big.Int).Sub(posReward, LockReward)
+			posReward, err := common.ParseQuantity(POSReward)
+			if err != nil {
+				return err
+			}
+			lockReward, err := common.ParseQuantity(LockReward)
+			if err != nil {
+				return err
+			}
+			totalReward := new(big.Int).Sub(posReward, lockReward)
 			rewardScheme = types.RewardSchemeDoc{
 				TotalReward:        totalReward,
 				RewardFirstYear:    new(big.Int).Div(totalReward, big.NewInt(8)),
@@ -255,6 +312,7 @@ func createGenesisDoc(config cfg.Config, chainId string, coreGenesis *core.Genes
 			Consensus:    types.CONSENSUS_POS,
 			GenesisTime:  time.Now(),
 			RewardScheme: rewardScheme,
+			BLSCurve:     bls.DefaultCurve.String(),
 			CurrentEpoch: types.OneEpochDoc{
 				Number:         0,
 				RewardPerBlock: rewardPerBlock,
@@ -267,8 +325,7 @@ func createGenesisDoc(config cfg.Config, chainId string, coreGenesis *core.Genes
 		if privValidator != nil {
 			coinbase, amount, checkErr := checkAccount(*coreGenesis)
 			if checkErr != nil {
-				log.Infof(checkErr.Error())
-				cmn.Exit(checkErr.Error())
+				return checkErr
 			}
 
 			genDoc.CurrentEpoch.Validators = []types.GenesisValidator{{
@@ -279,7 +336,10 @@ func createGenesisDoc(config cfg.Config, chainId string, coreGenesis *core.Genes
 		} else if validators != nil {
 			genDoc.CurrentEpoch.Validators = validators
 		}
-		genDoc.SaveAs(genFile)
+		if err := genDoc.SaveAs(genFile); err != nil {
+			return err
+		}
+		log.Infof("Genesis file %v created, genesis hash %x", genFile, genDoc.DocHashes.Genesis)
 	}
 	return nil
 }
@@ -340,10 +400,13 @@ func checkAccount(coreGenesis core.Genesis) (common.Address, *big.Int, error) {
 	return act, amount, nil
 }
 
-func initEthGenesisFromExistValidator(childChainID string, childConfig cfg.Config, validators []types.GenesisValidator) error {
+func initEthGenesisFromExistValidator(childChainID string, childConfig cfg.Config, validators []types.GenesisValidator, stateRent *params.StateRentConfig) error {
+
+	chainConfig := params.NewChildChainConfig(childChainID)
+	chainConfig.StateRent = stateRent
 
 	var coreGenesis = core.Genesis{
-		Config:     params.NewChildChainConfig(childChainID),
+		Config:     chainConfig,
 		Nonce:      0xdeadbeefdeadbeef,
 		Timestamp:  0x0,
 		ParentHash: common.Hash{},
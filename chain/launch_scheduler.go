@@ -0,0 +1,85 @@
+package chain
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/log"
+	dbm "github.com/tendermint/go-db"
+)
+
+// childChainLaunchScheduler computes the launch decision of the pending child chains for the next block in the
+// background, so that the block finalization only applies the precomputed decision to the state. The decision is
+// computed again in the finalization if the pending chain data has been changed since then
+type childChainLaunchScheduler struct {
+	db      dbm.DB
+	workers int
+
+	mtx      sync.Mutex
+	plan     *core.ChildChainLaunchPlan
+	planning *big.Int // height being computed in the background
+}
+
+func newChildChainLaunchScheduler(db dbm.DB, workers int) *childChainLaunchScheduler {
+	return &childChainLaunchScheduler{
+		db:      db,
+		workers: workers,
+	}
+}
+
+// schedule starts computing the decision at the height in the background
+func (s *childChainLaunchScheduler) schedule(height *big.Int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if (s.planning != nil && s.planning.Cmp(height) == 0) || (s.plan != nil && s.plan.Valid(height)) {
+		return
+	}
+	s.planning = new(big.Int).Set(height)
+
+	go func() {
+		plan := core.PlanChildChainLaunch(s.db, height, s.workers)
+
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		if s.planning != nil && s.planning.Cmp(height) == 0 {
+			s.planning = nil
+		}
+		// Don't replace the decision of a later height
+		if s.plan == nil || s.plan.Height.Cmp(height) <= 0 {
+			s.plan = plan
+		}
+	}()
+}
+
+// get returns the decision at the height, it's computed synchronously if the precomputed one is not available
+func (s *childChainLaunchScheduler) get(height *big.Int) *core.ChildChainLaunchPlan {
+	s.mtx.Lock()
+	plan := s.plan
+	s.mtx.Unlock()
+
+	if plan != nil && plan.Valid(height) {
+		return plan
+	}
+
+	log.Debugf("childChainLaunchScheduler - No precomputed launch decision for Block %v", height)
+	for {
+		plan = core.PlanChildChainLaunch(s.db, height, s.workers)
+		// Compute again if the pending chain data is changed during the computation
+		if plan.Valid(height) {
+			break
+		}
+	}
+
+	s.mtx.Lock()
+	s.plan = plan
+	s.mtx.Unlock()
+	return plan
+}
+
+// next schedules the decision of the block after the height
+func (s *childChainLaunchScheduler) next(height *big.Int) {
+	s.schedule(new(big.Int).Add(height, common.Big1))
+}
@@ -0,0 +1,233 @@
+package chain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	tmcfg "github.com/ethereum/go-ethereum/consensus/tendermint/config/tendermint"
+	"github.com/ethereum/go-ethereum/consensus/tendermint/types"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	cmn "github.com/tendermint/go-common"
+	cfg "github.com/tendermint/go-config"
+)
+
+const (
+	// LocalnetSubnet is the docker network of the local test network, node i gets the address 172.28.0.(10+i)
+	LocalnetSubnet = "172.28.0.0/16"
+
+	localnetP2PPort = 30308
+	localnetBalance = "1000000pi"
+	localnetStake   = "100000pi"
+)
+
+// LocalnetNode is one validator node of the local test network
+type LocalnetNode struct {
+	Name      string
+	DataDir   string
+	IP        net.IP
+	Port      int
+	RPCPort   int
+	Validator common.Address
+	Enode     string
+}
+
+// Localnet is the layout of the local test network, the main chain and the child chains are validated by all the nodes
+type Localnet struct {
+	Dir         string
+	Nodes       []*LocalnetNode
+	ChildChains []string
+}
+
+// GenerateLocalnet generates the keys, genesis, epoch files and configs of the local test network into the directory,
+// one data directory per node with the static peers pointing to all the other nodes. The nodes listen on the loopback
+// interface with the consecutive ports if inProcess is set, otherwise on their own address in the docker network.
+//
+// The child chains are generated with the genesis of all the validators, they are not registered in the main chain,
+// so the cross chain operations are not available to them
+func GenerateLocalnet(dir string, numValidators, numChildChains int, inProcess bool) (*Localnet, error) {
+
+	if numValidators < 1 {
+		return nil, errors.New("at least one validator is required")
+	}
+	if numChildChains < 0 {
+		return nil, errors.New("invalid number of child chains")
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("%v already exists", dir)
+	}
+
+	balance, err := common.ParseQuantity(localnetBalance)
+	if err != nil {
+		return nil, err
+	}
+	stake, err := common.ParseQuantity(localnetStake)
+	if err != nil {
+		return nil, err
+	}
+
+	localnet := &Localnet{Dir: dir}
+	configs := make([]cfg.Config, numValidators)
+	privValidators := make([]*types.PrivValidator, numValidators)
+	validators := make([]types.GenesisValidator, numValidators)
+
+	for i := 0; i < numValidators; i++ {
+		n := &LocalnetNode{
+			Name:    fmt.Sprintf("node%d", i),
+			Port:    localnetP2PPort,
+			RPCPort: node.DefaultHTTPPort,
+		}
+		n.DataDir = filepath.Join(dir, n.Name)
+		if inProcess {
+			n.IP = net.IPv4(127, 0, 0, 1)
+			n.Port += i
+			n.RPCPort += i
+		} else {
+			n.IP = net.IPv4(172, 28, 0, byte(10+i))
+		}
+
+		configs[i] = tmcfg.GetConfig(n.DataDir, MainChain)
+		privValidators[i] = createPriValidators(configs[i], 1)[0]
+		n.Validator = privValidators[i].Address
+		validators[i] = types.GenesisValidator{
+			EthAccount: privValidators[i].Address,
+			PubKey:     privValidators[i].PubKey,
+			Amount:     stake,
+		}
+
+		nodeKey, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := crypto.SaveECDSA(filepath.Join(n.DataDir, "nodekey"), nodeKey); err != nil {
+			return nil, err
+		}
+		n.Enode = discover.NewNode(discover.PubkeyID(&nodeKey.PublicKey), n.IP, uint16(n.Port), uint16(n.Port)).String()
+
+		localnet.Nodes = append(localnet.Nodes, n)
+	}
+
+	// Every node connects to all the other nodes
+	for _, n := range localnet.Nodes {
+		peers := make([]string, 0, len(localnet.Nodes)-1)
+		for _, peer := range localnet.Nodes {
+			if peer != n {
+				peers = append(peers, peer.Enode)
+			}
+		}
+		contents, err := json.MarshalIndent(peers, "", "\t")
+		if err != nil {
+			return nil, err
+		}
+		if err := cmn.WriteFile(filepath.Join(n.DataDir, "static-nodes.json"), contents, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	// Main Chain Genesis, the validators are pre-funded
+	coreGenesis := newMainChainGenesis()
+	for _, v := range validators {
+		coreGenesis.Alloc[v.EthAccount] = core.GenesisAccount{
+			Balance: balance,
+			Amount:  stake,
+		}
+	}
+	contents, err := json.MarshalIndent(coreGenesis, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	if err := cmn.WriteFile(configs[0].GetString("eth_genesis_file"), contents, 0644); err != nil {
+		return nil, err
+	}
+	if err := createGenesisDoc(configs[0], MainChain, &coreGenesis, nil, validators); err != nil {
+		return nil, err
+	}
+	if err := initLocalnetChain(localnet, MainChain, configs); err != nil {
+		return nil, err
+	}
+
+	// Child Chains use the same validators with the main chain
+	for k := 0; k < numChildChains; k++ {
+		chainId := fmt.Sprintf("child_%d", k)
+
+		childConfigs := make([]cfg.Config, numValidators)
+		for i, n := range localnet.Nodes {
+			childConfigs[i] = tmcfg.GetConfig(n.DataDir, chainId)
+
+			privValidators[i].SetFile(childConfigs[i].GetString("priv_validator_file_root") + ".json")
+			privValidators[i].Save()
+
+			if err := copyDir(configs[i].GetString("keystore"), childConfigs[i].GetString("keystore")); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := initEthGenesisFromExistValidator(chainId, childConfigs[0], validators, nil); err != nil {
+			return nil, err
+		}
+		if err := init_em_files(childConfigs[0], chainId, childConfigs[0].GetString("eth_genesis_file"), validators); err != nil {
+			return nil, err
+		}
+		if err := initLocalnetChain(localnet, chainId, childConfigs); err != nil {
+			return nil, err
+		}
+
+		localnet.ChildChains = append(localnet.ChildChains, chainId)
+	}
+
+	log.Infof("Localnet of %d validators and %d child chains generated in %v", numValidators, numChildChains, dir)
+	return localnet, nil
+}
+
+// initLocalnetChain copies the genesis of the chain generated in the first node to all the other nodes,
+// all the nodes must start from the identical genesis, then writes the genesis block of each node
+func initLocalnetChain(localnet *Localnet, chainId string, configs []cfg.Config) error {
+	for _, file := range []string{"eth_genesis_file", "genesis_file"} {
+		contents, err := cmn.ReadFile(configs[0].GetString(file))
+		if err != nil {
+			return err
+		}
+		for _, config := range configs[1:] {
+			if err := cmn.WriteFile(config.GetString(file), contents, 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, n := range localnet.Nodes {
+		init_eth_blockchain_in(n.DataDir, chainId, configs[i].GetString("eth_genesis_file"))
+	}
+	return nil
+}
+
+func copyDir(src, dst string) error {
+	files, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if err := cmn.EnsureDir(dst, 0700); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		contents, err := cmn.ReadFile(filepath.Join(src, file.Name()))
+		if err != nil {
+			return err
+		}
+		if err := cmn.WriteFile(filepath.Join(dst, file.Name()), contents, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,414 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"bls"
+	"github.com/ethereum/go-ethereum/cmd/geth"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/tendermint/epoch"
+	"github.com/ethereum/go-ethereum/consensus/tendermint/types"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	dbm "github.com/tendermint/go-db"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Number of recent committed blocks whose AppHash (state root) are recorded in the export
+const recoveryAppHashDepth = 64
+
+// RecoveryExport contains the consensus critical state of one node, used to bootstrap
+// a replacement validator quorum after the catastrophic loss of the existing one
+type RecoveryExport struct {
+	ChainID      string                `json:"chain_id"`
+	ExportTime   time.Time             `json:"export_time"`
+	RewardScheme types.RewardSchemeDoc `json:"reward_scheme"`
+	CurrentEpoch types.OneEpochDoc     `json:"current_epoch"`
+	// Public part of the local validator key, nil if the node is not a validator
+	Validator *types.GenesisValidator `json:"validator,omitempty"`
+	// Last committed block
+	LastHeight uint64      `json:"last_height"`
+	LastHash   common.Hash `json:"last_hash"`
+	// AppHash (state root) of recent committed blocks, key is the block height
+	AppHashes map[uint64]common.Hash `json:"app_hashes"`
+	// Chain config and the eth state of the last committed block, the genesis alloc of the continuation
+	ChainConfig *params.ChainConfig `json:"chain_config"`
+	Alloc       core.GenesisAlloc   `json:"alloc"`
+}
+
+// ExportValidatorSetCmd export the consensus critical state of a chain into json file
+func ExportValidatorSetCmd(ctx *cli.Context) error {
+
+	chainId := ctx.Args().First()
+	if chainId == "" {
+		chainId = MainChain
+		if ctx.GlobalBool(utils.TestnetFlag.Name) {
+			chainId = TestnetChain
+		}
+	}
+
+	outFile := ctx.Args().Get(1)
+	if outFile == "" {
+		outFile = chainId + "_recovery.json"
+	}
+
+	export, err := exportRecoveryState(ctx, chainId)
+	if err != nil {
+		utils.Fatalf("failed to export the validator set: %v", err)
+		return err
+	}
+
+	contents, err := json.MarshalIndent(export, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(outFile, contents, 0644); err != nil {
+		utils.Fatalf("failed to write the export file: %v", err)
+		return err
+	}
+
+	log.Infof("Validator set of chain %v (epoch %v, height %v) exported to %v", chainId, export.CurrentEpoch.Number, export.LastHeight, outFile)
+	return nil
+}
+
+func exportRecoveryState(ctx *cli.Context, chainId string) (*RecoveryExport, error) {
+
+	config := GetTendermintConfig(chainId, ctx)
+
+	// Epoch and Reward Scheme
+	epochDB := dbm.NewDB("epoch", config.GetString("db_backend"), config.GetString("db_dir"))
+	defer epochDB.Close()
+
+	ep, err := epoch.LoadLatestEpoch(epochDB, log.Root())
+	if err != nil {
+		return nil, err
+	}
+	if ep == nil {
+		return nil, fmt.Errorf("no epoch found for chain %v", chainId)
+	}
+	rs := ep.GetRewardScheme()
+	if rs == nil {
+		return nil, fmt.Errorf("no reward scheme found for chain %v", chainId)
+	}
+
+	export := &RecoveryExport{
+		ChainID:    chainId,
+		ExportTime: time.Now(),
+		RewardScheme: types.RewardSchemeDoc{
+			TotalReward:        rs.TotalReward,
+			RewardFirstYear:    rs.RewardFirstYear,
+			EpochNumberPerYear: rs.EpochNumberPerYear,
+			TotalYear:          rs.TotalYear,
+			TimeBasedEmission:  rs.IsTimeBasedEmission(),
+		},
+		CurrentEpoch: types.OneEpochDoc{
+			Number:         ep.Number,
+			RewardPerBlock: ep.RewardPerBlock,
+			StartBlock:     ep.StartBlock,
+			EndBlock:       ep.EndBlock,
+			Status:         ep.Status,
+		},
+		AppHashes: make(map[uint64]common.Hash),
+	}
+	for _, val := range ep.Validators.Validators {
+		export.CurrentEpoch.Validators = append(export.CurrentEpoch.Validators, types.GenesisValidator{
+			EthAccount:     common.BytesToAddress(val.Address),
+			PubKey:         val.PubKey,
+			Amount:         val.VotingPower,
+			RemainingEpoch: val.RemainingEpoch,
+		})
+	}
+
+	// Public part of the local validator
+	privValPath := config.GetString("priv_validator_file")
+	if _, err := os.Stat(privValPath); err == nil {
+		privVal, err := types.LoadPrivValidator(privValPath)
+		if err != nil {
+			return nil, err
+		}
+		export.Validator = &types.GenesisValidator{
+			EthAccount: privVal.Address,
+			PubKey:     privVal.PubKey,
+		}
+	}
+
+	// Last committed blocks
+	chainDb, err := ethdb.NewLDBDatabase(filepath.Join(utils.MakeDataDir(ctx), chainId, gethmain.ClientIdentifier, "chaindata"), 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer chainDb.Close()
+
+	headHash := core.GetHeadBlockHash(chainDb)
+	if headHash == (common.Hash{}) {
+		return nil, errors.New("no committed block found")
+	}
+	export.LastHash = headHash
+	export.LastHeight = core.GetBlockNumber(chainDb, headHash)
+
+	for i := uint64(0); i < recoveryAppHashDepth && i <= export.LastHeight; i++ {
+		height := export.LastHeight - i
+		header := core.GetHeader(chainDb, core.GetCanonicalHash(chainDb, height), height)
+		if header == nil {
+			break
+		}
+		export.AppHashes[height] = header.Root
+	}
+
+	// Eth state of the last committed block
+	root, ok := export.AppHashes[export.LastHeight]
+	if !ok {
+		return nil, fmt.Errorf("header of the last committed block %v not found", export.LastHeight)
+	}
+	if export.ChainConfig, err = core.GetChainConfig(chainDb, core.GetCanonicalHash(chainDb, 0)); err != nil {
+		return nil, err
+	}
+	statedb, err := state.New(root, state.NewDatabase(chainDb))
+	if err != nil {
+		return nil, err
+	}
+	if export.Alloc, err = exportGenesisAlloc(statedb); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// exportGenesisAlloc dump the accounts of the state into the genesis alloc. The genesis has no place for the reward
+// balance and the delegation not deposited (proxied and pending refund), they are settled into the balance. The
+// vesting schedules are not carried over.
+func exportGenesisAlloc(statedb *state.StateDB) (core.GenesisAlloc, error) {
+	dump := statedb.RawDump()
+	alloc := make(core.GenesisAlloc, len(dump.Accounts))
+	deposited := make(map[common.Address]*big.Int)
+	for hexAddr, dumpAccount := range dump.Accounts {
+		addr := common.HexToAddress(hexAddr)
+		account := core.GenesisAccount{
+			Balance:    new(big.Int).Add(statedb.GetBalance(addr), statedb.GetTotalRewardBalance(addr)),
+			Nonce:      statedb.GetNonce(addr),
+			Code:       statedb.GetCode(addr),
+			Candidate:  statedb.IsCandidate(addr),
+			Commission: statedb.GetCommission(addr),
+		}
+		if deposit := statedb.GetDepositBalance(addr); deposit.Sign() > 0 {
+			account.Amount = deposit
+		}
+
+		for key, value := range dumpAccount.Storage {
+			if key == "" {
+				return nil, fmt.Errorf("storage key preimage of account %x not found", addr)
+			}
+			_, content, _, err := rlp.Split(common.Hex2Bytes(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid storage value of account %x: %v", addr, err)
+			}
+			if account.Storage == nil {
+				account.Storage = make(map[common.Hash]common.Hash)
+			}
+			account.Storage[common.HexToHash(key)] = common.BytesToHash(content)
+		}
+
+		statedb.ForEachProxied(addr, func(delegator common.Address, proxiedBalance, depositProxiedBalance, pendingRefundBalance *big.Int) bool {
+			if depositProxiedBalance.Sign() > 0 {
+				if account.DepositProxiedDetail == nil {
+					account.DepositProxiedDetail = make(map[common.Address]*big.Int)
+				}
+				account.DepositProxiedDetail[delegator] = new(big.Int).Set(depositProxiedBalance)
+				if deposited[delegator] == nil {
+					deposited[delegator] = new(big.Int)
+				}
+				deposited[delegator].Add(deposited[delegator], depositProxiedBalance)
+			}
+			return true
+		})
+		alloc[addr] = account
+	}
+
+	// The delegation deposited is kept, the rest of the delegate balance is released
+	for addr, account := range alloc {
+		kept := deposited[addr]
+		if kept == nil {
+			kept = new(big.Int)
+		}
+		if kept.Sign() > 0 {
+			account.DelegateBalance = kept
+		}
+		if released := new(big.Int).Sub(statedb.GetDelegateBalance(addr), kept); released.Sign() > 0 {
+			account.Balance.Add(account.Balance, released)
+		}
+		alloc[addr] = account
+	}
+	return alloc, nil
+}
+
+// ContinueChainCmd merge the exports from the surviving/replacement nodes and generate
+// the genesis for the new chain continuation, and the eth genesis of the exported state next to it
+func ContinueChainCmd(ctx *cli.Context) error {
+
+	args := ctx.Args()
+	if len(args) < 2 {
+		utils.Fatalf("usage: continue_chain <output genesis.json> <export.json> [export.json ...]")
+	}
+
+	exports := make([]*RecoveryExport, 0, len(args)-1)
+	for _, file := range args[1:] {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			utils.Fatalf("failed to read the export file %v: %v", file, err)
+			return err
+		}
+		var export RecoveryExport
+		if err := json.Unmarshal(contents, &export); err != nil {
+			utils.Fatalf("failed to parse the export file %v: %v", file, err)
+			return err
+		}
+		exports = append(exports, &export)
+	}
+
+	genDoc, ethGenesis, err := makeContinuationGenesis(exports)
+	if err != nil {
+		utils.Fatalf("failed to generate the continuation genesis: %v", err)
+		return err
+	}
+
+	if err := genDoc.SaveAs(args[0]); err != nil {
+		return err
+	}
+	contents, err := json.MarshalIndent(ethGenesis, "", "\t")
+	if err != nil {
+		return err
+	}
+	ethGenesisPath := filepath.Join(filepath.Dir(args[0]), "eth_genesis.json")
+	if err := ioutil.WriteFile(ethGenesisPath, contents, 0644); err != nil {
+		utils.Fatalf("failed to write the eth genesis: %v", err)
+		return err
+	}
+
+	log.Infof("Continuation genesis of chain %v generated to %v and %v, continue from height %v (AppHash %x) with %v validators and %v accounts",
+		genDoc.ChainID, args[0], ethGenesisPath, genDoc.Continuation.Height, genDoc.Continuation.StateRoot,
+		len(genDoc.CurrentEpoch.Validators), len(ethGenesis.Alloc))
+	return nil
+}
+
+// makeContinuationGenesis check all the exports agree on the chain, epoch and the highest common committed block,
+// then build the new validator set from the validators who provided the export. The eth state is taken from the
+// exports of the common block, the genesis is refused if none of them has it or they don't agree on it
+func makeContinuationGenesis(exports []*RecoveryExport) (*types.GenesisDoc, *core.Genesis, error) {
+
+	base := exports[0]
+	for _, export := range exports[1:] {
+		if export.ChainID != base.ChainID {
+			return nil, nil, fmt.Errorf("chain id mismatch, %v vs %v", export.ChainID, base.ChainID)
+		}
+		if export.CurrentEpoch.Number != base.CurrentEpoch.Number {
+			return nil, nil, fmt.Errorf("epoch number mismatch, %v vs %v", export.CurrentEpoch.Number, base.CurrentEpoch.Number)
+		}
+	}
+
+	// Find the highest height with the same AppHash in all exports
+	heights := make([]uint64, 0, len(base.AppHashes))
+	for height := range base.AppHashes {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] > heights[j] })
+
+	var commonHeight uint64
+	found := false
+	for _, height := range heights {
+		agreed := true
+		for _, export := range exports[1:] {
+			if appHash, ok := export.AppHashes[height]; !ok || appHash != base.AppHashes[height] {
+				agreed = false
+				break
+			}
+		}
+		if agreed {
+			commonHeight, found = height, true
+			break
+		}
+	}
+	if !found {
+		return nil, nil, errors.New("no common committed block found in the exports")
+	}
+
+	var ethGenesis *core.Genesis
+	var baseEncoded []byte
+	for _, export := range exports {
+		if export.LastHeight != commonHeight || export.Alloc == nil {
+			continue
+		}
+		if export.ChainConfig == nil {
+			return nil, nil, fmt.Errorf("chain config not found in the export of the common block %v", commonHeight)
+		}
+		genesis := newMainChainGenesis()
+		genesis.Config, genesis.Alloc = export.ChainConfig, export.Alloc
+		// The exports of the same state root must have the same state
+		encoded, err := json.Marshal(&genesis)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ethGenesis == nil {
+			ethGenesis, baseEncoded = &genesis, encoded
+		} else if !bytes.Equal(encoded, baseEncoded) {
+			return nil, nil, fmt.Errorf("eth state mismatch at the common block %v", commonHeight)
+		}
+	}
+	if ethGenesis == nil {
+		return nil, nil, fmt.Errorf("none of the exports has the eth state of the common block %v, export again from the node stopped at it", commonHeight)
+	}
+
+	// The Validator of the export replace the consensus key of the same account in the epoch
+	replacements := make(map[common.Address]*types.GenesisValidator)
+	for _, export := range exports {
+		if export.Validator != nil {
+			replacements[export.Validator.EthAccount] = export.Validator
+		}
+	}
+
+	var validators []types.GenesisValidator
+	for _, val := range base.CurrentEpoch.Validators {
+		if replacement, ok := replacements[val.EthAccount]; ok {
+			val.PubKey = replacement.PubKey
+			validators = append(validators, val)
+		}
+	}
+	if len(validators) == 0 {
+		return nil, nil, errors.New("none of the exports comes from a validator of the current epoch")
+	}
+
+	genDoc := &types.GenesisDoc{
+		ChainID:      base.ChainID,
+		Consensus:    types.CONSENSUS_POS,
+		GenesisTime:  time.Now(),
+		RewardScheme: base.RewardScheme,
+		BLSCurve:     bls.DefaultCurve.String(),
+		Continuation: &types.ContinuationDoc{
+			Height:    commonHeight,
+			StateRoot: base.AppHashes[commonHeight],
+		},
+		CurrentEpoch: types.OneEpochDoc{
+			Number:         base.CurrentEpoch.Number,
+			RewardPerBlock: base.CurrentEpoch.RewardPerBlock,
+			StartBlock:     base.CurrentEpoch.StartBlock,
+			EndBlock:       base.CurrentEpoch.EndBlock,
+			Status:         base.CurrentEpoch.Status,
+			Validators:     validators,
+		},
+	}
+
+	return genDoc, ethGenesis, nil
+}
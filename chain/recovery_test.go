@@ -0,0 +1,147 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/tendermint/types"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/tendermint/go-crypto"
+)
+
+var (
+	recoveryVal1 = common.HexToAddress("0x01")
+	recoveryVal2 = common.HexToAddress("0x02")
+)
+
+func newRecoveryExport(val common.Address, lastHeight uint64, appHashes map[uint64]common.Hash, alloc core.GenesisAlloc) *RecoveryExport {
+	return &RecoveryExport{
+		ChainID: "pchain",
+		CurrentEpoch: types.OneEpochDoc{
+			Number: 3,
+			Validators: []types.GenesisValidator{
+				{EthAccount: recoveryVal1, PubKey: crypto.BLSPubKey{1}, Amount: big.NewInt(100)},
+				{EthAccount: recoveryVal2, PubKey: crypto.BLSPubKey{2}, Amount: big.NewInt(100)},
+			},
+		},
+		Validator:   &types.GenesisValidator{EthAccount: val, PubKey: crypto.BLSPubKey{9}},
+		LastHeight:  lastHeight,
+		AppHashes:   appHashes,
+		ChainConfig: params.MainnetChainConfig,
+		Alloc:       alloc,
+	}
+}
+
+func TestMakeContinuationGenesis(t *testing.T) {
+	root10, root11 := common.HexToHash("0x10"), common.HexToHash("0x11")
+	hashes := map[uint64]common.Hash{10: root10, 11: root11}
+	alloc := core.GenesisAlloc{recoveryVal1: {Balance: big.NewInt(5), Amount: big.NewInt(100)}}
+
+	genDoc, ethGenesis, err := makeContinuationGenesis([]*RecoveryExport{
+		newRecoveryExport(recoveryVal1, 11, hashes, alloc),
+		newRecoveryExport(recoveryVal2, 11, hashes, alloc),
+	})
+	if err != nil {
+		t.Fatalf("failed to make the continuation genesis: %v", err)
+	}
+	if c := genDoc.Continuation; c == nil || c.Height != 11 || c.StateRoot != root11 {
+		t.Errorf("continuation %+v, want height 11 and root %x", c, root11)
+	}
+	if len(genDoc.CurrentEpoch.Validators) != 2 || !genDoc.CurrentEpoch.Validators[0].PubKey.Equals(crypto.BLSPubKey{9}) {
+		t.Errorf("validators %v, want the keys of the exports", genDoc.CurrentEpoch.Validators)
+	}
+	if ethGenesis.Config != params.MainnetChainConfig || ethGenesis.Alloc[recoveryVal1].Balance.Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("eth genesis %v, want the exported state", ethGenesis)
+	}
+}
+
+func TestMakeContinuationGenesisUnmatched(t *testing.T) {
+	root10, root11 := common.HexToHash("0x10"), common.HexToHash("0x11")
+	hashes := map[uint64]common.Hash{10: root10, 11: root11}
+	alloc := core.GenesisAlloc{recoveryVal1: {Balance: big.NewInt(5)}}
+	other := core.GenesisAlloc{recoveryVal1: {Balance: big.NewInt(6)}}
+
+	tests := []struct {
+		name    string
+		exports []*RecoveryExport
+	}{
+		{
+			// The common block is 10, the state is exported at 11 only
+			name: "state not at the common block",
+			exports: []*RecoveryExport{
+				newRecoveryExport(recoveryVal1, 11, hashes, alloc),
+				newRecoveryExport(recoveryVal2, 11, map[uint64]common.Hash{10: root10, 11: common.HexToHash("0xff")}, alloc),
+			},
+		},
+		{
+			name: "state mismatch",
+			exports: []*RecoveryExport{
+				newRecoveryExport(recoveryVal1, 11, hashes, alloc),
+				newRecoveryExport(recoveryVal2, 11, hashes, other),
+			},
+		},
+		{
+			name: "state not exported",
+			exports: []*RecoveryExport{
+				newRecoveryExport(recoveryVal1, 11, hashes, nil),
+			},
+		},
+		{
+			name: "no common block",
+			exports: []*RecoveryExport{
+				newRecoveryExport(recoveryVal1, 11, hashes, alloc),
+				newRecoveryExport(recoveryVal2, 11, map[uint64]common.Hash{11: common.HexToHash("0xff")}, alloc),
+			},
+		},
+	}
+	for _, test := range tests {
+		if _, _, err := makeContinuationGenesis(test.exports); err == nil {
+			t.Errorf("%s: continuation genesis generated", test.name)
+		}
+	}
+}
+
+func TestExportGenesisAlloc(t *testing.T) {
+	candidate, delegator := common.HexToAddress("0xc0"), common.HexToAddress("0xd0")
+
+	db, _ := ethdb.NewMemDatabase()
+	stateDatabase := state.NewDatabase(db)
+	statedb, _ := state.New(common.Hash{}, stateDatabase)
+	statedb.AddBalance(candidate, big.NewInt(100))
+	statedb.AddDepositBalance(candidate, big.NewInt(50))
+	statedb.AddRewardBalanceByEpochNumber(candidate, 1, big.NewInt(7))
+	statedb.ApplyForCandidate(candidate, 10)
+	statedb.SetState(candidate, common.HexToHash("0x01"), common.HexToHash("0x0102"))
+	statedb.AddDelegateBalance(delegator, big.NewInt(30))
+	statedb.AddDepositProxiedBalanceByUser(candidate, delegator, big.NewInt(20))
+	statedb.AddProxiedBalanceByUser(candidate, delegator, big.NewInt(10))
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statedb, err = state.New(root, stateDatabase); err != nil {
+		t.Fatal(err)
+	}
+
+	alloc, err := exportGenesisAlloc(statedb)
+	if err != nil {
+		t.Fatalf("failed to export the alloc: %v", err)
+	}
+	c, d := alloc[candidate], alloc[delegator]
+	if c.Balance.Cmp(big.NewInt(107)) != 0 || c.Amount.Cmp(big.NewInt(50)) != 0 || !c.Candidate || c.Commission != 10 {
+		t.Errorf("candidate %+v, want the reward settled into the balance", c)
+	}
+	if c.Storage[common.HexToHash("0x01")] != common.HexToHash("0x0102") {
+		t.Errorf("storage %v not exported", c.Storage)
+	}
+	if c.DepositProxiedDetail[delegator].Cmp(big.NewInt(20)) != 0 {
+		t.Errorf("deposit proxied %v, want 20", c.DepositProxiedDetail)
+	}
+	if d.DelegateBalance.Cmp(big.NewInt(20)) != 0 || d.Balance.Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("delegator %+v, want the delegation not deposited released", d)
+	}
+}
@@ -0,0 +1,127 @@
+package chain
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/cmd/geth"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/tendermint/epoch"
+	"github.com/ethereum/go-ethereum/consensus/tendermint/types"
+	"github.com/ethereum/go-ethereum/log"
+	cmn "github.com/tendermint/go-common"
+	dbm "github.com/tendermint/go-db"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// shadowConfigTmpl is the tendermint config of the shadow fork, the node has no seeds since it's the only validator
+var shadowConfigTmpl = `# This is a TOML config file.
+# For more information, see https://github.com/toml-lang/toml
+moniker = "shadow"
+seeds = ""
+fast_sync = false
+db_backend = "leveldb"
+`
+
+// InitShadowFork initialises the shadow fork of the chain in the data directory from the data of the source node,
+// which should be stopped while copied. The chain data is copied without the keys of the source node, then the
+// validator with the largest voting power is taken over by a new local consensus key, so that the shadow node is
+// the only validator and keeps producing the blocks. The address and the voting power of the validator are kept,
+// so that the validator set is recalculated from the deposits in the state on the epoch switch as usual.
+// The shadow fork is initialised only once, it's continued if the data directory is already initialised
+func InitShadowFork(ctx *cli.Context, chainId, source string) error {
+
+	datadir := ctx.GlobalString(utils.DataDirFlag.Name)
+	if cmn.FileExists(filepath.Join(datadir, chainId, gethmain.ClientIdentifier, "chaindata")) {
+		log.Info("Shadow fork already initialised, continue it", "datadir", datadir)
+		return nil
+	}
+
+	sourceChainDir := filepath.Join(source, chainId)
+	if !cmn.FileExists(sourceChainDir) {
+		return fmt.Errorf("no data of chain %v in %v", chainId, source)
+	}
+	if err := copyChainData(sourceChainDir, filepath.Join(datadir, chainId)); err != nil {
+		return err
+	}
+	if sourceInfoDir := filepath.Join(source, "chaininfo.db"); cmn.FileExists(sourceInfoDir) {
+		if err := copyChainData(sourceInfoDir, filepath.Join(datadir, "chaininfo.db")); err != nil {
+			return err
+		}
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(datadir, "config.toml"), []byte(shadowConfigTmpl), 0644); err != nil {
+		return err
+	}
+	config := GetTendermintConfig(chainId, ctx)
+	Config = config
+
+	// Take over the largest validator of the latest epoch
+	epochDB := dbm.NewDB("epoch", config.GetString("db_backend"), config.GetString("db_dir"))
+	defer epochDB.Close()
+
+	ep, err := epoch.LoadLatestEpoch(epochDB, log.Root())
+	if err != nil {
+		return err
+	}
+	if ep == nil || ep.Validators == nil || ep.Validators.Size() == 0 {
+		return fmt.Errorf("no epoch found for chain %v", chainId)
+	}
+	var largest *types.Validator
+	for _, val := range ep.Validators.Validators {
+		if largest == nil || val.VotingPower.Cmp(largest.VotingPower) > 0 {
+			largest = val
+		}
+	}
+
+	privValidator := types.GenPrivValidatorKey(common.BytesToAddress(largest.Address))
+	privValidator.SetFile(config.GetString("priv_validator_file"))
+	privValidator.Save()
+
+	shadow := largest.Copy()
+	shadow.PubKey = privValidator.PubKey
+	ep.Validators = types.NewValidatorSet([]*types.Validator{shadow})
+	ep.Save()
+
+	log.Info("Shadow fork initialised", "datadir", datadir, "source", source, "epoch", ep.Number,
+		"validator", privValidator.Address, "votingPower", shadow.VotingPower)
+	return nil
+}
+
+// copyChainData copies the chain data directory recursively, the keys and the sockets of the source node are not copied
+func copyChainData(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		name := info.Name()
+		if name == "keystore" || name == "nodekey" || strings.HasPrefix(name, "priv_validator") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return cmn.EnsureDir(target, 0700)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, contents, 0600)
+	})
+}
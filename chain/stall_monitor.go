@@ -0,0 +1,174 @@
+package chain
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/pchain/rpc"
+)
+
+var (
+	stalledChainsGauge = metrics.NewRegisteredGauge("chain/childchain/stalled", nil)
+	chainStallMeter    = metrics.NewRegisteredMeter("chain/childchain/stall", nil)
+)
+
+// childChainStallMonitor watches the child chains running on this node, the chain is stalled if no block has been
+// inserted into it for the timeout. The stall is alerted, and if release is set, the RPC and the remote txs of the
+// chain are paused until the chain inserts a block again
+type childChainStallMonitor struct {
+	timeout time.Duration
+	release bool
+
+	mtx     sync.Mutex
+	chains  map[string]*chainActivity
+	stalled int // number of the chains stalled
+	quit    chan struct{}
+}
+
+// chainActivity is the time of the last block inserted into the chain, and the time the chain is stalled since
+type chainActivity struct {
+	chain        *Chain
+	lastBlock    time.Time
+	stalledSince time.Time // zero if the chain is not stalled
+}
+
+func newChildChainStallMonitor(timeout time.Duration, release bool) *childChainStallMonitor {
+	return &childChainStallMonitor{
+		timeout: timeout,
+		release: release,
+		chains:  make(map[string]*chainActivity),
+		quit:    make(chan struct{}),
+	}
+}
+
+// start checks the chains watched for the stalls in the background, the monitor is disabled if the timeout is 0
+func (m *childChainStallMonitor) start() {
+	if m.timeout == 0 {
+		return
+	}
+
+	interval := m.timeout / 4
+	if interval < time.Second {
+		interval = time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case now := <-ticker.C:
+				m.check(now)
+			case <-m.quit:
+				return
+			}
+		}
+	}()
+}
+
+func (m *childChainStallMonitor) stop() {
+	close(m.quit)
+}
+
+// watch follows the blocks inserted into the child chain started, the chain isn't stalled before the timeout has
+// elapsed since it's watched
+func (m *childChainStallMonitor) watch(chain *Chain) {
+	if m.timeout == 0 {
+		return
+	}
+	ethereum, err := getEthereumFromNode(chain.EthNode)
+	if err != nil {
+		log.Errorf("Child Chain %v stall not monitored: %v", chain.Id, err)
+		return
+	}
+
+	m.mtx.Lock()
+	m.chains[chain.Id] = &chainActivity{chain: chain, lastBlock: time.Now()}
+	m.mtx.Unlock()
+
+	ch := make(chan core.ChainEvent, 16)
+	sub := ethereum.BlockChain().SubscribeChainEvent(ch)
+	go func() {
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case <-ch:
+				m.blockInserted(chain.Id, time.Now())
+			case <-sub.Err():
+				return
+			case <-m.quit:
+				return
+			}
+		}
+	}()
+}
+
+// blockInserted records the block inserted into the chain, the stalled chain is recovered
+func (m *childChainStallMonitor) blockInserted(chainId string, now time.Time) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	activity := m.chains[chainId]
+	if activity == nil {
+		return
+	}
+	activity.lastBlock = now
+	if activity.stalledSince.IsZero() {
+		return
+	}
+
+	log.Info("Child chain recovered", "chain", chainId, "stalled", common.PrettyDuration(now.Sub(activity.stalledSince)))
+	activity.stalledSince = time.Time{}
+	m.stalled--
+	stalledChainsGauge.Update(int64(m.stalled))
+	if m.release {
+		m.pause(activity.chain, false)
+	}
+}
+
+// check marks the chains without block for the timeout as stalled
+func (m *childChainStallMonitor) check(now time.Time) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	for chainId, activity := range m.chains {
+		if !activity.stalledSince.IsZero() || now.Sub(activity.lastBlock) < m.timeout {
+			continue
+		}
+
+		log.Warn("Child chain stalled, no block inserted", "chain", chainId, "since", activity.lastBlock, "timeout", m.timeout)
+		activity.stalledSince = activity.lastBlock
+		m.stalled++
+		stalledChainsGauge.Update(int64(m.stalled))
+		chainStallMeter.Mark(1)
+		if m.release {
+			m.pause(activity.chain, true)
+		}
+	}
+}
+
+// pause stops serving the RPC and accepting the remote txs of the chain, or resumes them
+func (m *childChainStallMonitor) pause(chain *Chain, paused bool) {
+	rpc.PauseChain(chain.Id, paused)
+	if ethereum, err := getEthereumFromNode(chain.EthNode); err == nil {
+		ethereum.PauseTxs(paused)
+	}
+}
+
+// stalledSince returns the time of the last block of the stalled chain, false if the chain is not stalled or not
+// watched
+func (m *childChainStallMonitor) stalledSince(chainId string) (time.Time, bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	activity := m.chains[chainId]
+	if activity == nil || activity.stalledSince.IsZero() {
+		return time.Time{}, false
+	}
+	return activity.stalledSince, true
+}
@@ -0,0 +1,186 @@
+package chain
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	tmcfg "github.com/ethereum/go-ethereum/consensus/tendermint/config/tendermint"
+	"github.com/ethereum/go-ethereum/consensus/tendermint/types"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	cmn "github.com/tendermint/go-common"
+	"github.com/tendermint/go-crypto"
+)
+
+const (
+	sentryNodesFile   = "sentry-nodes.json"
+	validatorVoteFile = "validator_vote.json"
+)
+
+// ValidatorKeys are the keys and the files of the validator node set up by InitValidator
+type ValidatorKeys struct {
+	Account       common.Address   // account of the validator, signs its txs and receives its rewards
+	PubKey        crypto.BLSPubKey // consensus public key, bound to the account
+	Enode         string           // URL of the validator node the sentries connect to
+	Generated     bool             // false if the keys already in the data directory are reused
+	Keystore      string
+	PrivValidator string
+	NodeKey       string
+	StaticNodes   string // static and trusted nodes of the validator, the sentries, empty if no sentry is given
+	SentryNodes   string // static and trusted nodes of the sentries, the validator, empty if no sentry is given
+
+	privValidator *types.PrivValidator
+}
+
+// InitValidator sets up the validator node of the chain in the data directory. The account and the consensus key
+// bound to it are generated, unless the private validator of the chain already exists, then they are reused so that
+// the setup can be run again, e.g. once the account is funded. The node key is generated if missing.
+//
+// If the sentries are given, the validator is only connected to them, its static and trusted nodes are the sentries,
+// and the static and trusted nodes of the sentries, the validator, are written to sentry-nodes.json, the sentries
+// reach the validator at the ip and port
+func InitValidator(datadir, chainId, password string, ip net.IP, port int, sentries []string) (*ValidatorKeys, error) {
+
+	config := tmcfg.GetConfig(datadir, chainId)
+	keys := &ValidatorKeys{
+		Keystore:      config.GetString("keystore"),
+		PrivValidator: config.GetString("priv_validator_file"),
+		NodeKey:       filepath.Join(datadir, "nodekey"),
+	}
+	ks := keystore.NewKeyStore(keys.Keystore, keystore.StandardScryptN, keystore.StandardScryptP)
+
+	if cmn.FileExists(keys.PrivValidator) {
+		privValidator, err := types.LoadPrivValidator(keys.PrivValidator)
+		if err != nil {
+			return nil, err
+		}
+		if !ks.HasAddress(privValidator.Address) {
+			return nil, fmt.Errorf("account %x of %v not found in the keystore %v", privValidator.Address, keys.PrivValidator, keys.Keystore)
+		}
+		keys.privValidator = privValidator
+	} else {
+		account, err := ks.NewAccount(password)
+		if err != nil {
+			return nil, err
+		}
+		keys.privValidator = types.GenPrivValidatorKey(account.Address)
+		keys.privValidator.SetFile(keys.PrivValidator)
+		keys.privValidator.Save()
+		keys.Generated = true
+	}
+	keys.Account = keys.privValidator.Address
+	switch pubKey := keys.privValidator.PubKey.(type) {
+	case crypto.BLSPubKey:
+		keys.PubKey = pubKey
+	case *crypto.BLSPubKey:
+		keys.PubKey = *pubKey
+	default:
+		return nil, fmt.Errorf("unsupported consensus public key of type %T", pubKey)
+	}
+
+	nodeKey, err := ethcrypto.LoadECDSA(keys.NodeKey)
+	if err != nil {
+		if nodeKey, err = ethcrypto.GenerateKey(); err != nil {
+			return nil, err
+		}
+		if err := cmn.EnsureDir(datadir, 0700); err != nil {
+			return nil, err
+		}
+		if err := ethcrypto.SaveECDSA(keys.NodeKey, nodeKey); err != nil {
+			return nil, err
+		}
+	}
+	keys.Enode = discover.NewNode(discover.PubkeyID(&nodeKey.PublicKey), ip, uint16(port), uint16(port)).String()
+
+	if len(sentries) > 0 {
+		for _, sentry := range sentries {
+			if _, err := discover.ParseNode(sentry); err != nil {
+				return nil, fmt.Errorf("invalid sentry %v: %v", sentry, err)
+			}
+		}
+		keys.StaticNodes = filepath.Join(datadir, "static-nodes.json")
+		keys.SentryNodes = filepath.Join(datadir, sentryNodesFile)
+		if err := writeNodes(sentries, keys.StaticNodes, filepath.Join(datadir, "trusted-nodes.json")); err != nil {
+			return nil, err
+		}
+		if err := writeNodes([]string{keys.Enode}, keys.SentryNodes); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// writeNodes writes the node URLs to the node lists
+func writeNodes(nodes []string, files ...string) error {
+	contents, err := json.MarshalIndent(nodes, "", "\t")
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := cmn.WriteFile(file, contents, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidatorVote is the vote of the validator for the next epoch, its hash is sent in the vote stage of the epoch, then
+// the vote is revealed in the reveal stage. The salt must be kept until the vote is revealed.
+type ValidatorVote struct {
+	From      common.Address   `json:"from"`
+	PubKey    crypto.BLSPubKey `json:"pubKey"`
+	Amount    *hexutil.Big     `json:"amount"`
+	Salt      string           `json:"salt"`
+	Signature hexutil.Bytes    `json:"signature"` // consensus signature of the account, proves the key is bound to it
+	Hash      common.Hash      `json:"voteHash"`
+}
+
+// Vote returns the vote of the validator for the next epoch of the amount, stored in the chain directory of the
+// data directory. The vote stored of the same amount is reused, it may already be sent.
+func (keys *ValidatorKeys) Vote(amount *big.Int) (*ValidatorVote, string, error) {
+
+	file := filepath.Join(filepath.Dir(keys.PrivValidator), validatorVoteFile)
+	if contents, err := ioutil.ReadFile(file); err == nil {
+		var vote ValidatorVote
+		if err := json.Unmarshal(contents, &vote); err != nil {
+			return nil, "", fmt.Errorf("invalid vote %v: %v", file, err)
+		}
+		if vote.From == keys.Account && vote.Amount.ToInt().Cmp(amount) == 0 {
+			return &vote, file, nil
+		}
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, "", err
+	}
+	vote := &ValidatorVote{
+		From:      keys.Account,
+		PubKey:    keys.PubKey,
+		Amount:    (*hexutil.Big)(new(big.Int).Set(amount)),
+		Salt:      hexutil.Encode(salt),
+		Signature: keys.privValidator.Sign(keys.Account.Bytes()).Bytes(),
+	}
+	vote.Hash = ethcrypto.Keccak256Hash(vote.From.Bytes(), vote.PubKey.Bytes(), common.LeftPadBytes(amount.Bytes(), 1), []byte(vote.Salt))
+	if err := crypto.CheckConsensusPubKey(vote.From, vote.PubKey.Bytes(), vote.Signature); err != nil {
+		return nil, "", fmt.Errorf("consensus key not bound to the account: %v", err)
+	}
+
+	contents, err := json.MarshalIndent(vote, "", "\t")
+	if err != nil {
+		return nil, "", err
+	}
+	if err := cmn.WriteFile(file, contents, 0600); err != nil {
+		return nil, "", err
+	}
+	return vote, file, nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pchain/chain"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// exportChainCmd dumps the canonical blocks of the chain into the file in the RLP format of geth, gzipped if the file
+// name ends with .gz. The blocks are read by the running node of the chain, so the cold tier is covered as well.
+func exportChainCmd(ctx *cli.Context) error {
+
+	args := ctx.Args()
+	if len(args) != 2 && len(args) != 4 {
+		utils.Fatalf("usage: export-chain <chainId> <file> [first last]")
+	}
+
+	var first, last *uint64
+	if len(args) == 4 {
+		from, ferr := strconv.ParseUint(args[2], 10, 64)
+		to, lerr := strconv.ParseUint(args[3], 10, 64)
+		if ferr != nil || lerr != nil {
+			utils.Fatalf("block number not an unsigned integer")
+		}
+		first, last = &from, &to
+	}
+
+	client, file := dialChainNode(ctx, args[0], args[1])
+	defer client.Close()
+
+	start := time.Now()
+	var ok bool
+	if err := client.Call(&ok, "admin_exportChain", file, first, last); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Export chain %v failed: %v", args[0], err), 1)
+	}
+	fmt.Printf("Chain %v exported to %v in %v\n", args[0], file, time.Since(start))
+	return nil
+}
+
+// importChainCmd imports the RLP block dump into the chain through the running node of the chain. With --verify,
+// each block is re-executed through the consensus pipeline and its state root verified against the local execution.
+func importChainCmd(ctx *cli.Context) error {
+
+	args := ctx.Args()
+	if len(args) != 2 {
+		utils.Fatalf("usage: import-chain <chainId> <file> [--verify]")
+	}
+
+	client, file := dialChainNode(ctx, args[0], args[1])
+	defer client.Close()
+
+	start := time.Now()
+	var ok bool
+	if err := client.Call(&ok, "admin_importChain", file, ctx.Bool(ImportVerifyFlag.Name)); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Import chain %v failed: %v", args[0], err), 1)
+	}
+	fmt.Printf("Chain %v imported from %v in %v\n", args[0], file, time.Since(start))
+	return nil
+}
+
+// repairReceiptsCmd re-executes the blocks of the chain through the running node of the chain and compares the derived
+// receipts with the stored ones, the differing receipts are replaced unless --dry-run is set
+func repairReceiptsCmd(ctx *cli.Context) error {
+
+	args := ctx.Args()
+	if len(args) != 3 {
+		utils.Fatalf("usage: repair-receipts <chainId> <first> <last> [--dry-run]")
+	}
+	first, ferr := strconv.ParseUint(args[1], 10, 64)
+	last, lerr := strconv.ParseUint(args[2], 10, 64)
+	if ferr != nil || lerr != nil {
+		utils.Fatalf("block number not an unsigned integer")
+	}
+
+	client := attachChainNode(ctx, args[0])
+	defer client.Close()
+
+	start := time.Now()
+	dryRun := ctx.Bool(RepairDryRunFlag.Name)
+	var mismatches []struct {
+		Number   uint64 `json:"number"`
+		Tx       int    `json:"tx"`
+		Reason   string `json:"reason"`
+		Repaired bool   `json:"repaired"`
+	}
+	if err := client.Call(&mismatches, "admin_repairReceipts", first, last, dryRun); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Repair receipts of chain %v failed: %v", args[0], err), 1)
+	}
+	repaired := 0
+	for _, m := range mismatches {
+		fmt.Printf("Block %d tx %d: %v, repaired: %v\n", m.Number, m.Tx, m.Reason, m.Repaired)
+		if m.Repaired {
+			repaired++
+		}
+	}
+	fmt.Printf("Receipts of blocks %d - %d checked in %v, %d blocks mismatched, %d repaired\n", first, last,
+		time.Since(start), len(mismatches), repaired)
+	return nil
+}
+
+// reindexTxCmd rebuilds the tx hash to block index of the chain from the block store through the running node of the
+// chain, and verifies the samples of the blocks against their receipts
+func reindexTxCmd(ctx *cli.Context) error {
+
+	args := ctx.Args()
+	if len(args) != 1 && len(args) != 3 {
+		utils.Fatalf("usage: reindex-tx <chainId> [<first> <last>] [--samples N]")
+	}
+	first, last := uint64(0), uint64(math.MaxUint64)
+	if len(args) == 3 {
+		var ferr, lerr error
+		first, ferr = strconv.ParseUint(args[1], 10, 64)
+		last, lerr = strconv.ParseUint(args[2], 10, 64)
+		if ferr != nil || lerr != nil {
+			utils.Fatalf("block number not an unsigned integer")
+		}
+	}
+
+	client := attachChainNode(ctx, args[0])
+	defer client.Close()
+
+	start := time.Now()
+	var report struct {
+		First      uint64 `json:"first"`
+		Last       uint64 `json:"last"`
+		Txs        uint64 `json:"txs"`
+		Rewritten  uint64 `json:"rewritten"`
+		Pruned     uint64 `json:"pruned"`
+		Verified   uint64 `json:"verified"`
+		Mismatches []struct {
+			Number uint64      `json:"number"`
+			TxHash common.Hash `json:"txHash"`
+			Tx     int         `json:"tx"`
+			Reason string      `json:"reason"`
+		} `json:"mismatches"`
+	}
+	if err := client.Call(&report, "admin_reindexTxs", first, last, ctx.Int(ReindexSamplesFlag.Name)); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Reindex txs of chain %v failed: %v", args[0], err), 1)
+	}
+	for _, m := range report.Mismatches {
+		fmt.Printf("Block %d tx %d %x: %v mismatch\n", m.Number, m.Tx, m.TxHash, m.Reason)
+	}
+	fmt.Printf("Tx index of blocks %d - %d rebuilt in %v, %d txs, %d entries rewritten, %d pruned, %d blocks verified, %d mismatches\n",
+		report.First, report.Last, time.Since(start), report.Txs, report.Rewritten, report.Pruned, report.Verified, len(report.Mismatches))
+	if len(report.Mismatches) > 0 {
+		return cli.NewExitError("Tx index mismatches the receipts", 1)
+	}
+	return nil
+}
+
+// snapshotHoldersCmd writes the CSV of the token holders of the chain at the height through the running node of the
+// chain, for the airdrops and the governance snapshots
+func snapshotHoldersCmd(ctx *cli.Context) error {
+
+	args := ctx.Args()
+	if len(args) != 2 {
+		utils.Fatalf("usage: snapshot-holders <chainId> <file.csv> [--height N] [--min-balance X]")
+	}
+	var number interface{} = "latest"
+	if height := ctx.String(SnapshotHeightFlag.Name); height != "latest" {
+		n, err := strconv.ParseUint(height, 10, 64)
+		if err != nil {
+			utils.Fatalf("height not an unsigned integer")
+		}
+		number = hexutil.Uint64(n)
+	}
+	minBalance, err := common.ParseQuantity(ctx.String(SnapshotMinBalanceFlag.Name))
+	if err != nil {
+		utils.Fatalf("invalid minimum balance: %v", err)
+	}
+
+	client, file := dialChainNode(ctx, args[0], args[1])
+	defer client.Close()
+
+	start := time.Now()
+	var snapshot struct {
+		Number  hexutil.Uint64 `json:"number"`
+		Holders hexutil.Uint64 `json:"holders"`
+		Total   *hexutil.Big   `json:"total"`
+	}
+	if err := client.Call(&snapshot, "admin_snapshotHolders", file, number, (*hexutil.Big)(minBalance)); err != nil {
+		return cli.NewExitError(fmt.Sprintf("Snapshot holders of chain %v failed: %v", args[0], err), 1)
+	}
+	fmt.Printf("%d holders of chain %v at block %d, total %v, written to %v in %v\n", snapshot.Holders, args[0],
+		snapshot.Number, snapshot.Total.ToInt(), file, time.Since(start))
+	return nil
+}
+
+// dialChainNode connects to the IPC endpoint of the running node of the chain, returns the absolute path of the file
+// since the file is opened by the node
+func dialChainNode(ctx *cli.Context, chainId, file string) (*rpc.Client, string) {
+
+	path, err := filepath.Abs(file)
+	if err != nil {
+		utils.Fatalf("invalid file %v: %v", file, err)
+	}
+	return attachChainNode(ctx, chainId), path
+}
+
+// attachChainNode connects to the IPC endpoint of the running node of the chain
+func attachChainNode(ctx *cli.Context, chainId string) *rpc.Client {
+
+	if chainId == "" {
+		chainId = chain.MainChain
+	}
+	endpoint := filepath.Join(utils.MakeDataDir(ctx), chainId, clientIdentifier+".ipc")
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		utils.Fatalf("Unable to attach to the node of chain %v at %v: %v", chainId, endpoint, err)
+	}
+	return client
+}
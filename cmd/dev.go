@@ -0,0 +1,43 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pchain/chain"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// devCmd boots an ephemeral single validator chain for the dApp developers, the chain data is kept in memory
+// and discarded on exit, blocks are sealed as soon as the transactions arrive
+func devCmd(ctx *cli.Context) error {
+
+	// Keys and genesis files of the developer chain go to a temporary data directory
+	datadir, err := ioutil.TempDir("", "pchain-dev")
+	if err != nil {
+		log.Errorf("Create developer data directory failed. %v", err)
+		return err
+	}
+	defer os.RemoveAll(datadir)
+
+	ctx.GlobalIsSet(utils.DataDirFlag.Name) // initialise the set flags before overriding them
+	ctx.GlobalSet(utils.DataDirFlag.Name, datadir)
+	ctx.GlobalSet(utils.DeveloperFlag.Name, "true")
+	ctx.GlobalSet(utils.RPCEnabledFlag.Name, "true")
+	if !ctx.GlobalIsSet(utils.RPCApiFlag.Name) {
+		ctx.GlobalSet(utils.RPCApiFlag.Name, chain.AllRPCModules)
+	}
+	if !ctx.GlobalIsSet(utils.WSApiFlag.Name) {
+		ctx.GlobalSet(utils.WSApiFlag.Name, chain.AllRPCModules)
+	}
+
+	chainId := chain.MainChain
+	if err := chain.InitDevChain(ctx, chainId); err != nil {
+		log.Errorf("Init developer chain failed. %v", err)
+		return err
+	}
+
+	return pchainCmd(ctx)
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state/selftest"
 	"gopkg.in/urfave/cli.v1"
 	"runtime"
 )
@@ -23,6 +25,119 @@ var (
 		Usage: "Specify one or more child chain should be start. Ex: child-1,child-2",
 	}
 
+	// Localnet Flags
+	LocalnetValidatorsFlag = cli.IntFlag{
+		Name:  "validators",
+		Usage: "Number of the validator nodes in the local test network",
+		Value: 4,
+	}
+	LocalnetChildChainsFlag = cli.IntFlag{
+		Name:  "child-chains",
+		Usage: "Number of the child chains validated by all the nodes of the local test network",
+	}
+	LocalnetDirFlag = cli.StringFlag{
+		Name:  "dir",
+		Usage: "Directory of the generated local test network",
+		Value: "localnet",
+	}
+	LocalnetImageFlag = cli.StringFlag{
+		Name:  "image",
+		Usage: "Docker image with the pchain binary used by docker-compose",
+		Value: "pchain",
+	}
+	LocalnetRunFlag = cli.BoolFlag{
+		Name:  "run",
+		Usage: "Run all the nodes of the local test network on this host until interrupted",
+	}
+
+	// Selftest Flags
+	SelftestSeedFlag = cli.Int64Flag{
+		Name:  "seed",
+		Usage: "Seed of the random operations, 0 for a random seed",
+	}
+	SelftestStepsFlag = cli.IntFlag{
+		Name:  "steps",
+		Usage: "Number of the operations applied in each run",
+		Value: selftest.DefaultConfig.Steps,
+	}
+	SelftestRunsFlag = cli.IntFlag{
+		Name:  "runs",
+		Usage: "Number of the runs, the seed is increased by one for each run",
+		Value: 1,
+	}
+
+	// Chain Import Flags
+	ImportVerifyFlag = cli.BoolFlag{
+		Name:  "verify",
+		Usage: "Re-execute each imported block through the consensus pipeline and verify its state root",
+	}
+	RepairDryRunFlag = cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Only report the blocks whose stored receipts differ, without repairing them",
+	}
+	ReindexSamplesFlag = cli.IntFlag{
+		Name:  "samples",
+		Usage: "Number of the blocks sampled to verify the rebuilt tx index against their receipts, 0 for all",
+		Value: core.DefaultTxIndexSamples,
+	}
+
+	// Token Holder Snapshot Flags
+	SnapshotHeightFlag = cli.StringFlag{
+		Name:  "height",
+		Usage: "Block number the holders are snapshotted at",
+		Value: "latest",
+	}
+	SnapshotMinBalanceFlag = cli.StringFlag{
+		Name:  "min-balance",
+		Usage: "Minimum total held by the address snapshotted, in wei or with the unit, e.g. 100pi",
+		Value: "0",
+	}
+
+	// Validator Setup Flags
+	ValidatorDepositFlag = cli.StringFlag{
+		Name:  "deposit",
+		Usage: "Amount voted as the deposit of the validator for the next epoch, in wei or with the unit, e.g. 100000pi",
+		Value: "100000pi",
+	}
+	ValidatorSecurityDepositFlag = cli.StringFlag{
+		Name:  "security-deposit",
+		Usage: "Security deposit of the validator applying as the candidate of the delegations, 0 not to apply",
+		Value: "10000pi",
+	}
+	ValidatorCommissionFlag = cli.UintFlag{
+		Name:  "commission",
+		Usage: "Commission of the candidate on the rewards of the delegations, in percent",
+		Value: 10,
+	}
+	ValidatorSentriesFlag = cli.StringFlag{
+		Name:  "sentries",
+		Usage: "Comma separated enode URLs of the sentry nodes, the only peers of the validator",
+	}
+	ValidatorIPFlag = cli.StringFlag{
+		Name:  "ip",
+		Usage: "IP address the sentry nodes reach the validator at",
+		Value: "127.0.0.1",
+	}
+	ValidatorBroadcastFlag = cli.BoolFlag{
+		Name:  "broadcast",
+		Usage: "Send the candidate application and the vote of the validator through the running node of the chain",
+	}
+
+	// Vendor Verification Flags
+	VerifyVendorRootFlag = cli.StringFlag{
+		Name:  "root",
+		Usage: "Root of the repository whose vendored sources are verified",
+		Value: ".",
+	}
+	VerifyVendorWriteFlag = cli.BoolFlag{
+		Name:  "write",
+		Usage: "Regenerate the release manifest from the vendored sources instead of verifying them",
+	}
+	AllowPatchedConsensusFlag = cli.BoolFlag{
+		Name:  "consensus.allowpatched",
+		Usage: "Start the validator even if the consensus code of the binary differs from the release manifest",
+	}
+
 	// ----------------------------
 	// Tendermint Flags
 
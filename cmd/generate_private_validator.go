@@ -28,7 +28,12 @@ func GeneratePrivateValidatorCmd(ctx *cli.Context) error {
 
 	privValFile := filepath.Join(ctx.GlobalString(utils.DataDirFlag.Name), "priv_validator.json")
 
-	validator := types.GenPrivValidatorKey(common.HexToAddress(address))
+	addr, err := common.ParseAddress(address)
+	if err != nil {
+		return err
+	}
+
+	validator := types.GenPrivValidatorKey(addr)
 	fmt.Printf(string(wire.JSONBytesPretty(validator)))
 	validator.SetFile(privValFile)
 	validator.Save()
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/template"
+	"time"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pchain/chain"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// localnetStopTimeout is how long the nodes are given to shut down gracefully before they are killed
+const localnetStopTimeout = 15 * time.Second
+
+var dockerComposeTmpl = template.Must(template.New("docker-compose").Parse(`version: "3"
+services:
+{{- range .Nodes}}
+  {{.Name}}:
+    image: {{$.Image}}
+    command: [{{range $i, $arg := .Args}}{{if $i}}, {{end}}"{{$arg}}"{{end}}]
+    volumes:
+      - ./{{.Name}}:/data
+    ports:
+      - "{{.HostRPCPort}}:{{.RPCPort}}"
+    networks:
+      localnet:
+        ipv4_address: {{.IP}}
+{{- end}}
+networks:
+  localnet:
+    ipam:
+      config:
+        - subnet: {{.Subnet}}
+`))
+
+// localnetCmd generates the local test network, the nodes are started by docker-compose, or by this command
+// if the run flag is set
+func localnetCmd(ctx *cli.Context) error {
+
+	run := ctx.Bool(LocalnetRunFlag.Name)
+	localnet, err := chain.GenerateLocalnet(ctx.String(LocalnetDirFlag.Name),
+		ctx.Int(LocalnetValidatorsFlag.Name), ctx.Int(LocalnetChildChainsFlag.Name), run)
+	if err != nil {
+		log.Errorf("Generate localnet failed. %v", err)
+		return err
+	}
+
+	if !run {
+		composeFile := filepath.Join(localnet.Dir, "docker-compose.yml")
+		if err := writeDockerCompose(localnet, ctx.String(LocalnetImageFlag.Name), composeFile); err != nil {
+			log.Errorf("Write docker-compose file failed. %v", err)
+			return err
+		}
+		fmt.Printf("Localnet generated, start it with: docker-compose -f %s up\n", composeFile)
+		return nil
+	}
+
+	return runLocalnet(localnet)
+}
+
+// localnetNodeArgs returns the command line of the node
+func localnetNodeArgs(localnet *chain.Localnet, n *chain.LocalnetNode, datadir, rpcAddr string) []string {
+	args := []string{
+		"--" + utils.DataDirFlag.Name, datadir,
+		"--" + LogDirFlag.Name, filepath.Join(datadir, "log"),
+		"--" + utils.ListenPortFlag.Name, strconv.Itoa(n.Port),
+		"--" + utils.NoDiscoverFlag.Name,
+		"--" + utils.RPCEnabledFlag.Name,
+		"--" + utils.RPCListenAddrFlag.Name, rpcAddr,
+		"--" + utils.RPCPortFlag.Name, strconv.Itoa(n.RPCPort),
+		"--" + utils.RPCVirtualHostsFlag.Name, "*",
+		"--" + utils.RPCApiFlag.Name, chain.AllRPCModules,
+	}
+	if len(localnet.ChildChains) > 0 {
+		args = append(args, "--"+ChildChainFlag.Name, strings.Join(localnet.ChildChains, ","))
+	}
+	return args
+}
+
+// writeDockerCompose writes the docker-compose file of the local test network, the data directory of each node
+// is mounted into its container, and the RPC of node i is published on the host port 6969+i
+func writeDockerCompose(localnet *chain.Localnet, image, path string) error {
+
+	type service struct {
+		*chain.LocalnetNode
+		Args        []string
+		HostRPCPort int
+	}
+
+	services := make([]service, len(localnet.Nodes))
+	for i, n := range localnet.Nodes {
+		services[i] = service{
+			LocalnetNode: n,
+			Args:         append([]string{"pchain"}, localnetNodeArgs(localnet, n, "/data", "0.0.0.0")...),
+			HostRPCPort:  n.RPCPort + i,
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return dockerComposeTmpl.Execute(f, map[string]interface{}{
+		"Image":  image,
+		"Nodes":  services,
+		"Subnet": chain.LocalnetSubnet,
+	})
+}
+
+// runLocalnet runs every node of the local test network in a child process of this binary, since the chain manager
+// is a process wide singleton, and stops all the nodes once interrupted or any of them exits
+func runLocalnet(localnet *chain.Localnet) error {
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmds := make([]*exec.Cmd, 0, len(localnet.Nodes))
+	exited := make(chan error, len(localnet.Nodes))
+	signalAll := func(sig os.Signal) {
+		for _, cmd := range cmds {
+			cmd.Process.Signal(sig)
+		}
+	}
+
+	for _, n := range localnet.Nodes {
+		logFile, err := os.Create(filepath.Join(n.DataDir, "node.log"))
+		if err != nil {
+			signalAll(os.Kill)
+			return err
+		}
+
+		cmd := exec.Command(exe, localnetNodeArgs(localnet, n, n.DataDir, "127.0.0.1")...)
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+		if err := cmd.Start(); err != nil {
+			logFile.Close()
+			signalAll(os.Kill)
+			return err
+		}
+		cmds = append(cmds, cmd)
+		go func() {
+			exited <- cmd.Wait()
+			logFile.Close()
+		}()
+
+		log.Info("Localnet node started", "node", n.Name, "validator", n.Validator, "pid", cmd.Process.Pid,
+			"rpc", fmt.Sprintf("http://127.0.0.1:%d/%s", n.RPCPort, chain.MainChain))
+	}
+
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	running := len(cmds)
+	select {
+	case <-sigc:
+		log.Info("Got interrupt, stopping the localnet...")
+	case err := <-exited:
+		running--
+		log.Error("Localnet node exited, stopping the localnet...", "err", err)
+	}
+
+	signalAll(os.Interrupt)
+	timeout := time.After(localnetStopTimeout)
+	for running > 0 {
+		select {
+		case <-exited:
+			running--
+		case <-timeout:
+			log.Warn("Localnet nodes did not stop in time, killing them")
+			signalAll(os.Kill)
+			timeout = nil
+		}
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/pchain/chain"
+	perrors "github.com/pchain/errors"
 	"github.com/pchain/version"
 	"gopkg.in/urfave/cli.v1"
 	"os"
@@ -50,6 +51,174 @@ func main() {
 			Description: "Initialize the files",
 		},
 
+		{
+			Action:      chain.GenesisHashCmd,
+			Name:        "genesis_hash",
+			Usage:       "genesis_hash genesis.json",
+			Description: "Print the canonical hashes of the genesis documents",
+		},
+
+		{
+			Action:      chain.ExportValidatorSetCmd,
+			Name:        "export_validator_set",
+			Usage:       "export_validator_set chainId output.json",
+			Description: "Export the consensus critical state (epoch, validator public keys, recent AppHash) for disaster recovery",
+		},
+
+		{
+			Action:      chain.ContinueChainCmd,
+			Name:        "continue_chain",
+			Usage:       "continue_chain genesis.json export1.json export2.json ...",
+			Description: "Generate the genesis of the new chain continuation from the validator set exports, and the eth_genesis.json of the exported state next to it",
+		},
+
+		{
+			Action:      exportChainCmd,
+			Name:        "export-chain",
+			Usage:       "export-chain chainId file.rlp[.gz] [first last]",
+			Description: "Export the blocks of the chain in the RLP format of geth through the running node of the chain",
+		},
+
+		{
+			Action: importChainCmd,
+			Name:   "import-chain",
+			Usage:  "import-chain chainId file.rlp[.gz] [--verify]",
+			Flags: []cli.Flag{
+				ImportVerifyFlag,
+			},
+			Description: "Import the blocks in the RLP format of geth into the chain through the running node of the chain, with --verify each block is re-executed and its state root verified",
+		},
+
+		{
+			Action: repairReceiptsCmd,
+			Name:   "repair-receipts",
+			Usage:  "repair-receipts chainId first last [--dry-run]",
+			Flags: []cli.Flag{
+				RepairDryRunFlag,
+			},
+			Description: "Re-execute the blocks of the chain through the running node of the chain and repair the stored receipts differing from the derived ones, including the log indexes and the blooms",
+		},
+
+		{
+			Action: reindexTxCmd,
+			Name:   "reindex-tx",
+			Usage:  "reindex-tx chainId [first last] [--samples N]",
+			Flags: []cli.Flag{
+				ReindexSamplesFlag,
+			},
+			Description: "Rebuild the tx hash to block index of the canonical blocks of the chain from the block store through the running node of the chain, all the blocks if the range is not given, and verify the samples of the blocks against their receipts. Only the entries missing or differing are written, the rebuild can be run again",
+		},
+
+		{
+			Action: snapshotHoldersCmd,
+			Name:   "snapshot-holders",
+			Usage:  "snapshot-holders chainId file.csv [--height N] [--min-balance X]",
+			Flags: []cli.Flag{
+				SnapshotHeightFlag,
+				SnapshotMinBalanceFlag,
+			},
+			Description: "Write the CSV of the addresses of the chain holding at least the minimum at the height, with the breakdown of their balances, vesting locks, deposits, delegations and rewards, through the running node of the chain, the historical state is regenerated if pruned",
+		},
+
+		{
+			Action: verifyVendorCmd,
+			Name:   "verify-vendor",
+			Usage:  "verify-vendor [--root .] [--write]",
+			Flags: []cli.Flag{
+				VerifyVendorRootFlag,
+				VerifyVendorWriteFlag,
+			},
+			Description: "Hash the consensus critical vendored sources (core, state, consensus/tendermint) and compare them against the release manifest, with --write the manifest is regenerated for the release",
+		},
+
+		{
+			Action: utils.MigrateFlags(devCmd),
+			Name:   "dev",
+			Usage:  "dev --dev.accounts address1,address2",
+			Flags: []cli.Flag{
+				utils.DeveloperPeriodFlag,
+				utils.DeveloperAccountsFlag,
+				utils.DeveloperBalanceFlag,
+			},
+			Description: "Start an ephemeral single validator chain with in-memory databases and pre-funded accounts for dApp development",
+		},
+
+		{
+			Action: utils.MigrateFlags(shadowForkCmd),
+			Name:   "shadow-fork",
+			Usage:  "shadow-fork --shadow.source datadir --shadow.replay url [--shadow.config override.json]",
+			Flags: []cli.Flag{
+				utils.ShadowForkSourceFlag,
+				utils.ShadowForkReplayFlag,
+				utils.ShadowForkConfigFlag,
+				utils.RewardPluginFlag,
+				utils.RepairConsistencyFlag,
+			},
+			Description: "Copy the chain data of an existing node and continue the chain locally as its only validator with the overridden config, replaying the txs of the real chain, to rehearse the network upgrades against the production traffic",
+		},
+
+		{
+			Action: localnetCmd,
+			Name:   "testnet",
+			Usage:  "testnet --validators 4 --child-chains 1 [--dir localnet] [--run]",
+			Flags: []cli.Flag{
+				LocalnetValidatorsFlag,
+				LocalnetChildChainsFlag,
+				LocalnetDirFlag,
+				LocalnetImageFlag,
+				LocalnetRunFlag,
+			},
+			Description: "Generate the keys, genesis and node configs of a local multi-node network for docker-compose, or run all the nodes on this host",
+		},
+
+		{
+			Name:  "validator",
+			Usage: "Set up and manage the validator of the chain",
+			Subcommands: []cli.Command{
+				{
+					Action: utils.MigrateFlags(validatorInitCmd),
+					Name:   "init",
+					Usage:  "init [chainId] [--deposit 100000pi] [--security-deposit 10000pi] [--commission 10] [--sentries enode1,enode2] [--ip 127.0.0.1] [--broadcast]",
+					Flags: []cli.Flag{
+						utils.DataDirFlag,
+						utils.PasswordFileFlag,
+						utils.ListenPortFlag,
+						ValidatorDepositFlag,
+						ValidatorSecurityDepositFlag,
+						ValidatorCommissionFlag,
+						ValidatorSentriesFlag,
+						ValidatorIPFlag,
+						ValidatorBroadcastFlag,
+					},
+					Description: "Generate the account, the consensus key bound to it and the node key of the validator, or reuse the ones in the data directory, connect the validator only to its sentries and write the peers of the sentries, then vote the deposit for the next epoch. With --broadcast the candidate application and the vote are sent through the running node of the chain, the vote is revealed with the salt stored. The values not given are prompted for, the summary is printed as JSON",
+				},
+			},
+		},
+
+		{
+			Name:  "selftest",
+			Usage: "Run the randomised self tests and the fixture replays against the in-memory state",
+			Subcommands: []cli.Command{
+				{
+					Action: selftestStateCmd,
+					Name:   "state",
+					Usage:  "state [--seed 1] [--steps 2000] [--runs 1]",
+					Flags: []cli.Flag{
+						SelftestSeedFlag,
+						SelftestStepsFlag,
+						SelftestRunsFlag,
+					},
+					Description: "Apply random sequences of delegate/undelegate/slash/reward operations to the StateDB and check the invariants after every step",
+				},
+				{
+					Action:      selftestReplayCmd,
+					Name:        "replay",
+					Usage:       "replay",
+					Description: "Replay the embedded fixture chains with delegation, cross chain and epoch switch activity and compare their final state roots",
+				},
+			},
+		},
+
 		{
 			Action:      GenerateNodeInfoCmd,
 			Name:        "gen_node_info",
@@ -114,7 +283,7 @@ func main() {
 
 	if err := cliApp.Run(os.Args); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(perrors.ExitCode(err))
 	}
 }
 
@@ -158,16 +327,28 @@ func newCliApp(version, usage string) *cli.App {
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolRecheckIntervalFlag,
 		//utils.FastSyncFlag,
 		//utils.LightModeFlag,
 		utils.SyncModeFlag,
 		utils.GCModeFlag,
+		utils.StateDiffFlag,
+		utils.WitnessFlag,
+		utils.AddressStatsFlag,
+		utils.DiskQuotaFlag,
+		utils.DiskQuotaWebhookFlag,
+		utils.ColdDataDirFlag,
+		utils.ColdWindowFlag,
+		utils.SnapshotBlocksFlag,
+		utils.SnapshotIntervalFlag,
+		utils.SnapshotRetentionFlag,
 		//utils.LightServFlag,
 		//utils.LightPeersFlag,
 		//utils.LightKDFFlag,
 		utils.CacheFlag,
 		utils.CacheDatabaseFlag,
 		utils.CacheGCFlag,
+		utils.CacheReceiptsFlag,
 		utils.TrieCacheGenFlag,
 		utils.ListenPortFlag,
 		utils.MaxPeersFlag,
@@ -176,6 +357,8 @@ func newCliApp(version, usage string) *cli.App {
 		utils.MinerGasTargetFlag,
 		utils.MinerGasLimitFlag,
 		utils.MinerGasPriceFlag,
+		utils.MinerMaxTxsFlag,
+		utils.MinerMaxGasFlag,
 		utils.MinerEtherbaseFlag,
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
@@ -183,21 +366,34 @@ func newCliApp(version, usage string) *cli.App {
 		utils.NetrestrictFlag,
 		utils.NodeKeyFileFlag,
 		utils.NodeKeyHexFlag,
-		//utils.DeveloperFlag,
-		//utils.DeveloperPeriodFlag,
+		utils.DeveloperFlag,
+		utils.DeveloperPeriodFlag,
+		utils.DeveloperAccountsFlag,
+		utils.DeveloperBalanceFlag,
+		utils.ShadowForkSourceFlag,
+		utils.ShadowForkReplayFlag,
+		utils.ShadowForkConfigFlag,
 		utils.TestnetFlag,
 		//utils.RinkebyFlag,
 		//utils.OttomanFlag,
 		utils.VMEnableDebugFlag,
+		utils.VMTxTimeoutFlag,
+		utils.VMBlockTimeoutFlag,
+		utils.VMSelfCheckFlag,
+		utils.RewardPluginFlag,
+		utils.RepairConsistencyFlag,
 		utils.NetworkIdFlag,
 
 		utils.EthStatsURLFlag,
+		utils.TelemetryURLFlag,
+		utils.TelemetryIntervalFlag,
 		utils.MetricsEnabledFlag,
 		utils.FakePoWFlag,
 		utils.NoCompactionFlag,
 		utils.GpoBlocksFlag,
 		utils.GpoPercentileFlag,
 		utils.ExtraDataFlag,
+		utils.BlockTagFlag,
 		//gethmain.ConfigFileFlag,
 		//utils.IstanbulRequestTimeoutFlag,
 		//utils.IstanbulBlockPeriodFlag,
@@ -208,12 +404,22 @@ func newCliApp(version, usage string) *cli.App {
 		utils.RPCApiFlag,
 		utils.RPCCORSDomainFlag,
 		utils.RPCVirtualHostsFlag,
+		utils.RPCBatchParallelFlag,
+		utils.RPCBatchGasFlag,
+		utils.RPCBatchTimeoutFlag,
+		utils.RPCTrustedFlag,
+		utils.RPCCallGasCapFlag,
+		utils.RPCCallMemoryFlag,
+		utils.RPCCallTimeoutFlag,
 		// RPC WS Flag
 		utils.WSEnabledFlag,
 		utils.WSListenAddrFlag,
 		utils.WSPortFlag,
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
+		utils.FirehoseAddrFlag,
+		utils.TxStreamAddrFlag,
+		utils.TxStreamKeysFlag,
 
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
@@ -224,9 +430,13 @@ func newCliApp(version, usage string) *cli.App {
 		//utils.WhisperMinPOWFlag,
 
 		utils.PerfTestFlag,
+		utils.ChildChainWatchWorkersFlag,
+		utils.ChildChainStallTimeoutFlag,
+		utils.ChildChainStallReleaseFlag,
 
 		LogDirFlag,
 		ChildChainFlag,
+		AllowPatchedConsensusFlag,
 
 		/*
 			//Tendermint flags
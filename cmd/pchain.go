@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/pchain/chain"
 	"gopkg.in/urfave/cli.v1"
@@ -15,9 +16,19 @@ func pchainCmd(ctx *cli.Context) error {
 		return nil
 	}
 
+	// --dev flag is the same as the dev command
+	if ctx.GlobalBool(utils.DeveloperFlag.Name) && ctx.Command.Name != "dev" {
+		return devCmd(ctx)
+	}
+
 	log.Info("Starting PChain...")
 	log.Info("PChain supports large scale block-chain applications with multi-chain")
 
+	if err := checkConsensusCode(ctx); err != nil {
+		log.Errorf("Check Consensus Code failed. %v", err)
+		return err
+	}
+
 	chainMgr := chain.GetCMInstance(ctx)
 
 	// ChildChainFlag flag
@@ -30,11 +41,14 @@ func pchainCmd(ctx *cli.Context) error {
 	err := chainMgr.LoadMainChain(ctx)
 	if err != nil {
 		log.Errorf("Load Main Chain failed. %v", err)
-		return nil
+		return err
 	}
 
 	//set the event.TypeMutex to cch
-	chainMgr.InitCrossChainHelper()
+	if err := chainMgr.InitCrossChainHelper(); err != nil {
+		log.Errorf("Init Cross Chain Helper failed. %v", err)
+		return err
+	}
 
 	// Start P2P Server
 	err = chainMgr.StartP2PServer()
@@ -69,6 +83,8 @@ func pchainCmd(ctx *cli.Context) error {
 
 	chainMgr.StartInspectEvent()
 
+	chainMgr.StartStallMonitor()
+
 	chainMgr.WaitChainsStop()
 
 	chainMgr.Stop()
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/state/selftest"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// selftestStateCmd runs the delegation state transitions with the random operations, the seed of the failed run is
+// printed so that it can be reproduced
+func selftestStateCmd(ctx *cli.Context) error {
+
+	config := selftest.DefaultConfig
+	config.Seed = ctx.Int64(SelftestSeedFlag.Name)
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+	config.Steps = ctx.Int(SelftestStepsFlag.Name)
+
+	for i := 0; i < ctx.Int(SelftestRunsFlag.Name); i++ {
+		result, err := selftest.RunDelegation(config)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("State selftest failed: %v", err), 1)
+		}
+
+		names := make([]string, 0, len(result.Ops))
+		for name := range result.Ops {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Printf("seed %d: ok, root %x", config.Seed, result.Root)
+		for _, name := range names {
+			fmt.Printf(", %s %d", name, result.Ops[name])
+		}
+		fmt.Printf(", skipped %d, reverts %d, commits %d\n", result.Skipped, result.Reverts, result.Commits)
+
+		config.Seed++
+	}
+	return nil
+}
+
+// selftestReplayCmd replays the fixture chains embedded in the binary and compares their final state roots, the
+// mismatch means the state transitions or the commit of the state changed
+func selftestReplayCmd(ctx *cli.Context) error {
+
+	results, err := selftest.CheckFixtures()
+	for _, result := range results {
+		fmt.Printf("%s: %d blocks, %d ops, root %x\n", result.Name, result.Blocks, result.Ops, result.Root)
+	}
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Fixture replay failed: %v", err), 1)
+	}
+	fmt.Printf("%d fixtures ok\n", len(results))
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pchain/chain"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// shadowForkCmd continues the copy of the main chain locally with the modified config or binary, the node is
+// isolated from the network and replays the txs of the real chain, so the upgrades are rehearsed against the
+// production traffic
+func shadowForkCmd(ctx *cli.Context) error {
+
+	source := ctx.GlobalString(utils.ShadowForkSourceFlag.Name)
+	if source == "" {
+		return errors.New("shadow.source is required")
+	}
+	if !ctx.GlobalIsSet(utils.DataDirFlag.Name) || filepath.Clean(utils.MakeDataDir(ctx)) == filepath.Clean(source) {
+		return errors.New("datadir of the shadow fork is required, it must not be the source")
+	}
+
+	// The shadow node must not reach the peers of the real chain
+	ctx.GlobalSet(utils.NoDiscoverFlag.Name, "true")
+	ctx.GlobalSet(utils.MaxPeersFlag.Name, "0")
+
+	chainId := chain.MainChain
+	if ctx.GlobalBool(utils.TestnetFlag.Name) {
+		chainId = chain.TestnetChain
+	}
+	if err := chain.InitShadowFork(ctx, chainId, source); err != nil {
+		log.Errorf("Init shadow fork failed. %v", err)
+		return err
+	}
+
+	return pchainCmd(ctx)
+}
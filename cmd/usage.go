@@ -77,20 +77,43 @@ var AppHelpFlagGroups = []flagGroup{
 			//utils.OttomanFlag,
 			utils.SyncModeFlag,
 			utils.GCModeFlag,
+			utils.StateDiffFlag,
+			utils.WitnessFlag,
+			utils.AddressStatsFlag,
+			utils.DiskQuotaFlag,
+			utils.DiskQuotaWebhookFlag,
+			utils.ColdDataDirFlag,
+			utils.ColdWindowFlag,
+			utils.SnapshotBlocksFlag,
+			utils.SnapshotIntervalFlag,
+			utils.SnapshotRetentionFlag,
 			utils.EthStatsURLFlag,
+			utils.TelemetryURLFlag,
+			utils.TelemetryIntervalFlag,
 			utils.IdentityFlag,
 			//utils.LightServFlag,
 			//utils.LightPeersFlag,
 			//utils.LightKDFFlag,
 		},
 	},
-	/*
-		{Name: "DEVELOPER CHAIN",
-			Flags: []cli.Flag{
-				utils.DeveloperFlag,
-				utils.DeveloperPeriodFlag,
-			},
+	{
+		Name: "DEVELOPER CHAIN",
+		Flags: []cli.Flag{
+			utils.DeveloperFlag,
+			utils.DeveloperPeriodFlag,
+			utils.DeveloperAccountsFlag,
+			utils.DeveloperBalanceFlag,
+		},
+	},
+	{
+		Name: "SHADOW FORK",
+		Flags: []cli.Flag{
+			utils.ShadowForkSourceFlag,
+			utils.ShadowForkReplayFlag,
+			utils.ShadowForkConfigFlag,
 		},
+	},
+	/*
 		{
 			Name: "ETHASH",
 			Flags: []cli.Flag{
@@ -126,6 +149,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolRecheckIntervalFlag,
 		},
 	},
 	{
@@ -134,6 +158,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.CacheFlag,
 			utils.CacheDatabaseFlag,
 			utils.CacheGCFlag,
+			utils.CacheReceiptsFlag,
 			utils.TrieCacheGenFlag,
 		},
 	},
@@ -160,10 +185,21 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.WSApiFlag,
 			utils.WSAllowedOriginsFlag,
 
+			utils.FirehoseAddrFlag,
+			utils.TxStreamAddrFlag,
+			utils.TxStreamKeysFlag,
+
 			utils.IPCDisabledFlag,
 			utils.IPCPathFlag,
 			utils.RPCCORSDomainFlag,
 			utils.RPCVirtualHostsFlag,
+			utils.RPCBatchParallelFlag,
+			utils.RPCBatchGasFlag,
+			utils.RPCBatchTimeoutFlag,
+			utils.RPCTrustedFlag,
+			utils.RPCCallGasCapFlag,
+			utils.RPCCallMemoryFlag,
+			utils.RPCCallTimeoutFlag,
 			//utils.JSpathFlag,
 			//utils.ExecFlag,
 			//utils.PreloadJSFlag,
@@ -193,8 +229,11 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.MinerGasPriceFlag,
 			utils.MinerGasTargetFlag,
 			utils.MinerGasLimitFlag,
+			utils.MinerMaxTxsFlag,
+			utils.MinerMaxGasFlag,
 			utils.MinerEtherbaseFlag,
 			utils.ExtraDataFlag,
+			utils.BlockTagFlag,
 		},
 	},
 	{
@@ -208,6 +247,17 @@ var AppHelpFlagGroups = []flagGroup{
 		Name: "VIRTUAL MACHINE",
 		Flags: []cli.Flag{
 			utils.VMEnableDebugFlag,
+			utils.VMTxTimeoutFlag,
+			utils.VMBlockTimeoutFlag,
+			utils.VMSelfCheckFlag,
+		},
+	},
+	{
+		Name: "CONSENSUS",
+		Flags: []cli.Flag{
+			utils.RewardPluginFlag,
+			utils.RepairConsistencyFlag,
+			AllowPatchedConsensusFlag,
 		},
 	},
 	{
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/console"
+	"github.com/mattn/go-isatty"
+	"github.com/pchain/chain"
+	cmn "github.com/tendermint/go-common"
+	"github.com/tendermint/go-crypto"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// validatorUnlockSeconds is how long the account of the validator is unlocked in the node to send its txs
+const validatorUnlockSeconds = 60
+
+// validatorSummary is the machine readable result of the validator setup, printed as JSON
+type validatorSummary struct {
+	ChainId         string                 `json:"chainId"`
+	Account         common.Address         `json:"account"`
+	RewardAddress   common.Address         `json:"rewardAddress"` // the rewards of the validator are paid to its account
+	ConsensusPubKey crypto.BLSPubKey       `json:"consensusPubKey"`
+	Enode           string                 `json:"enode"`
+	Generated       bool                   `json:"generated"`
+	Files           map[string]string      `json:"files"`
+	Sentries        []string               `json:"sentries"`
+	SecurityDeposit *hexutil.Big           `json:"securityDeposit"`
+	Commission      uint8                  `json:"commission"`
+	Deposit         *hexutil.Big           `json:"deposit"`
+	VoteHash        common.Hash            `json:"voteHash"`
+	Txs             map[string]common.Hash `json:"txs"`
+	Args            []string               `json:"args"` // command line of the validator node
+	Error           string                 `json:"error,omitempty"`
+}
+
+// validatorInitCmd sets up the validator of the chain in the data directory: the account and the consensus key bound
+// to it, the node key, the peers of the validator and its sentries, and the vote of the deposit for the next epoch.
+// With --broadcast the candidate application and the vote are sent through the running node of the chain, the vote
+// is revealed in the reveal stage of the epoch with the salt stored. The values not given by the flags are prompted
+// for on the terminal, the summary is printed as JSON.
+func validatorInitCmd(ctx *cli.Context) error {
+
+	chainId := ctx.Args().First()
+	if chainId == "" {
+		chainId = chain.MainChain
+	}
+	interactive := isatty.IsTerminal(os.Stdin.Fd())
+
+	deposit, err := common.ParseQuantity(promptFlag(ctx, interactive, ValidatorDepositFlag.Name, "Deposit voted for the next epoch"))
+	if err != nil {
+		utils.Fatalf("invalid deposit: %v", err)
+	}
+	securityDeposit, err := common.ParseQuantity(promptFlag(ctx, interactive, ValidatorSecurityDepositFlag.Name, "Security deposit of the candidate, 0 not to apply"))
+	if err != nil {
+		utils.Fatalf("invalid security deposit: %v", err)
+	}
+	commission, err := strconv.ParseUint(promptFlag(ctx, interactive, ValidatorCommissionFlag.Name, "Commission of the candidate in percent"), 10, 8)
+	if err != nil || commission > 100 {
+		utils.Fatalf("commission not a percent")
+	}
+	sentries := make([]string, 0)
+	for _, sentry := range strings.Split(promptFlag(ctx, interactive, ValidatorSentriesFlag.Name, "Comma separated enode URLs of the sentries"), ",") {
+		if sentry = strings.TrimSpace(sentry); sentry != "" {
+			sentries = append(sentries, sentry)
+		}
+	}
+	ip := net.ParseIP(ctx.String(ValidatorIPFlag.Name))
+	if ip == nil {
+		utils.Fatalf("invalid IP address %v", ctx.String(ValidatorIPFlag.Name))
+	}
+	broadcast := ctx.Bool(ValidatorBroadcastFlag.Name)
+	if !broadcast && interactive {
+		if broadcast, err = console.Stdin.PromptConfirm("Send the candidate application and the vote through the running node?"); err != nil {
+			utils.Fatalf("Failed to read confirmation: %v", err)
+		}
+	}
+
+	// The password is only required to generate the account or to send its txs
+	var password string
+	generate := !cmn.FileExists(chain.GetTendermintConfig(chainId, ctx).GetString("priv_validator_file"))
+	if generate || broadcast {
+		prompt := "Your validator account is locked with a password. Please give a password. Do not forget this password."
+		if !generate {
+			prompt = "Unlocking the validator account"
+		}
+		password = getPassPhrase(prompt, generate, 0, utils.MakePasswordList(ctx))
+	}
+
+	datadir := utils.MakeDataDir(ctx)
+	port := ctx.GlobalInt(utils.ListenPortFlag.Name)
+	keys, err := chain.InitValidator(datadir, chainId, password, ip, port, sentries)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Validator setup of chain %v failed: %v", chainId, err), 1)
+	}
+	vote, voteFile, err := keys.Vote(deposit)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Vote of the validator failed: %v", err), 1)
+	}
+
+	summary := &validatorSummary{
+		ChainId:         chainId,
+		Account:         keys.Account,
+		RewardAddress:   keys.Account,
+		ConsensusPubKey: keys.PubKey,
+		Enode:           keys.Enode,
+		Generated:       keys.Generated,
+		Files: map[string]string{
+			"keystore":      keys.Keystore,
+			"privValidator": keys.PrivValidator,
+			"nodeKey":       keys.NodeKey,
+			"vote":          voteFile,
+		},
+		Sentries:        sentries,
+		SecurityDeposit: (*hexutil.Big)(securityDeposit),
+		Commission:      uint8(commission),
+		Deposit:         vote.Amount,
+		VoteHash:        vote.Hash,
+		Txs:             make(map[string]common.Hash),
+		Args:            []string{"--" + utils.DataDirFlag.Name, datadir, "--" + utils.ListenPortFlag.Name, strconv.Itoa(port)},
+	}
+	if len(sentries) > 0 {
+		summary.Files["staticNodes"] = keys.StaticNodes
+		summary.Files["sentryNodes"] = keys.SentryNodes
+		summary.Args = append(summary.Args, "--"+utils.NoDiscoverFlag.Name)
+	}
+
+	var berr error
+	if broadcast {
+		berr = broadcastValidator(ctx, chainId, password, securityDeposit, uint8(commission), vote, summary.Txs)
+		if berr != nil {
+			summary.Error = berr.Error()
+		}
+	}
+
+	out, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	if berr != nil {
+		return cli.NewExitError(fmt.Sprintf("Broadcast of the validator txs failed: %v", berr), 1)
+	}
+	return nil
+}
+
+// promptFlag returns the value of the flag, prompted for with its default if not set and the stdin is a terminal
+func promptFlag(ctx *cli.Context, interactive bool, name, prompt string) string {
+	value := ctx.String(name)
+	if !interactive || ctx.IsSet(name) {
+		return value
+	}
+	input, err := console.Stdin.PromptInput(fmt.Sprintf("%s [%s]: ", prompt, value))
+	if err != nil {
+		utils.Fatalf("Failed to read %v: %v", name, err)
+	}
+	if input = strings.TrimSpace(input); input != "" {
+		return input
+	}
+	return value
+}
+
+// broadcastValidator unlocks the account of the validator in the running node of the chain, and sends its candidate
+// application unless it's already the candidate or the security deposit is 0, then the hash of its vote. The hashes
+// of the txs sent are added to txs.
+func broadcastValidator(ctx *cli.Context, chainId, password string, securityDeposit *big.Int, commission uint8,
+	vote *chain.ValidatorVote, txs map[string]common.Hash) error {
+
+	client := attachChainNode(ctx, chainId)
+	defer client.Close()
+
+	var unlocked bool
+	if err := client.Call(&unlocked, "personal_unlockAccount", vote.From, password, validatorUnlockSeconds); err != nil {
+		return err
+	}
+	defer client.Call(nil, "personal_lockAccount", vote.From)
+
+	if securityDeposit.Sign() > 0 {
+		var candidate struct {
+			Candidate bool `json:"candidate"`
+		}
+		if err := client.Call(&candidate, "del_checkCandidate", vote.From, "latest"); err != nil {
+			return err
+		}
+		if !candidate.Candidate {
+			var hash common.Hash
+			if err := client.Call(&hash, "del_applyCandidate", vote.From, (*hexutil.Big)(securityDeposit), commission, nil); err != nil {
+				return fmt.Errorf("apply candidate: %v", err)
+			}
+			txs["applyCandidate"] = hash
+		}
+	}
+
+	var hash common.Hash
+	if err := client.Call(&hash, "tdm_voteNextEpoch", vote.From, vote.Hash, nil); err != nil {
+		return fmt.Errorf("vote next epoch: %v", err)
+	}
+	txs["voteNextEpoch"] = hash
+	return nil
+}
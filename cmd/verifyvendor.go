@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pchain/chain"
+	perrors "github.com/pchain/errors"
+	"github.com/pchain/vendorcheck"
+	"github.com/pchain/version"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// consensusManifestSource is the Go source carrying the digest of the release manifest into the binary
+const consensusManifestSource = `// Code generated by pchain verify-vendor --write. DO NOT EDIT.
+
+package version
+
+// ConsensusManifest is the digest of the release manifest of the consensus critical sources
+const ConsensusManifest = %q
+`
+
+// verifyVendorCmd hashes the consensus critical vendored sources of the repository and compares them against the
+// release manifest, the files differing are printed. With --write the manifest and its digest compiled into the
+// binary are regenerated, which is done once per release.
+func verifyVendorCmd(ctx *cli.Context) error {
+
+	root := ctx.String(VerifyVendorRootFlag.Name)
+	sources, err := vendorcheck.Hash(root)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Hash the vendored sources failed: %v", err), 1)
+	}
+
+	manifestFile := filepath.Join(root, filepath.FromSlash(vendorcheck.ManifestFile))
+	if ctx.Bool(VerifyVendorWriteFlag.Name) {
+		if err := ioutil.WriteFile(manifestFile, sources.Bytes(), 0644); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Write the manifest failed: %v", err), 1)
+		}
+		source := fmt.Sprintf(consensusManifestSource, sources.Digest())
+		if err := ioutil.WriteFile(filepath.Join(filepath.Dir(manifestFile), "consensus_manifest.go"), []byte(source), 0644); err != nil {
+			return cli.NewExitError(fmt.Sprintf("Write the manifest digest failed: %v", err), 1)
+		}
+		fmt.Printf("Manifest of %d files written to %s, digest %s\n", len(sources), manifestFile, sources.Digest())
+		return nil
+	}
+
+	release, err := vendorcheck.Load(manifestFile)
+	if err != nil {
+		return cli.NewExitError(fmt.Sprintf("Load the release manifest failed: %v", err), 1)
+	}
+	diff := vendorcheck.Diff(release, sources)
+	for _, file := range diff {
+		fmt.Println(file)
+	}
+	fmt.Printf("sources %s, release manifest %s\n", sources.Digest(), release.Digest())
+	if version.ConsensusDigest != "" {
+		fmt.Printf("binary %s\n", version.ConsensusDigest)
+	}
+
+	if len(diff) > 0 {
+		return cli.NewExitError(fmt.Sprintf("Consensus code differs from the release manifest in %d files", len(diff)), 1)
+	}
+	if release.Digest() != version.ConsensusManifest {
+		return cli.NewExitError("Release manifest differs from the digest compiled into the binary, regenerate it with --write", 1)
+	}
+	return nil
+}
+
+// checkConsensusCode refuses to start the validator whose binary is built from the consensus code differing from the
+// release manifest, unless allowed with --consensus.allowpatched. The node without the private validator is not checked.
+func checkConsensusCode(ctx *cli.Context) error {
+
+	if _, err := os.Stat(chain.Config.GetString("priv_validator_file")); err != nil {
+		return nil
+	}
+
+	switch {
+	case version.ConsensusDigest == "":
+		log.Warn("Consensus code of the binary unknown, build it with build/env.sh to verify against the release manifest")
+	case version.ConsensusDigest != version.ConsensusManifest:
+		if ctx.GlobalBool(AllowPatchedConsensusFlag.Name) {
+			log.Warn("Consensus code differs from the release manifest, validating with the patched binary", "binary", version.ConsensusDigest, "release", version.ConsensusManifest)
+			return nil
+		}
+		return perrors.New(perrors.InvalidConfig, "checkConsensusCode", "consensus code of the binary %s differs from the release manifest %s, "+
+			"run pchain verify-vendor on the sources or start with --%s", version.ConsensusDigest, version.ConsensusManifest, AllowPatchedConsensusFlag.Name)
+	default:
+		log.Info("Consensus code verified against the release manifest", "digest", version.ConsensusDigest)
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package rpc
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/log"
@@ -9,7 +13,9 @@ import (
 	"gopkg.in/urfave/cli.v1"
 	"net"
 	"net/http"
+	"os"
 	"strings"
+	"sync"
 )
 
 var (
@@ -21,6 +27,15 @@ var (
 	wsMux            *http.ServeMux
 	wsOrigins        []string
 	wsHandlerMapping map[string]*rpc.Server
+
+	firehoseListener net.Listener
+	firehoseMux      *http.ServeMux
+
+	txStreamListener net.Listener
+	txStreamMux      *http.ServeMux
+
+	// chains whose HTTP and WS RPC is paused
+	pausedChains sync.Map
 )
 
 func StartRPC(ctx *cli.Context) error {
@@ -43,6 +58,16 @@ func StartRPC(ctx *cli.Context) error {
 		return wserr
 	}
 
+	fherr := startFirehose(ctx.GlobalString(utils.FirehoseAddrFlag.Name))
+	if fherr != nil {
+		return fherr
+	}
+
+	tserr := startTxStream(ctx.GlobalString(utils.TxStreamAddrFlag.Name), ctx.GlobalString(utils.TxStreamKeysFlag.Name))
+	if tserr != nil {
+		return tserr
+	}
+
 	return nil
 }
 
@@ -72,6 +97,22 @@ func StopRPC() {
 			wsHandler.Stop()
 		}
 	}
+
+	// Stop Firehose Listener
+	if firehoseListener != nil {
+		firehoseAddr := firehoseListener.Addr().String()
+		firehoseListener.Close()
+		firehoseListener = nil
+		log.Info("Firehose endpoint closed", "url", fmt.Sprintf("http://%s", firehoseAddr))
+	}
+
+	// Stop Tx Stream Listener
+	if txStreamListener != nil {
+		txStreamAddr := txStreamListener.Addr().String()
+		txStreamListener.Close()
+		txStreamListener = nil
+		log.Info("Tx stream endpoint closed", "url", fmt.Sprintf("ws://%s", txStreamAddr))
+	}
 }
 
 func IsHTTPRunning() bool {
@@ -82,11 +123,19 @@ func IsWSRunning() bool {
 	return wsListener != nil && wsMux != nil
 }
 
+func IsFirehoseRunning() bool {
+	return firehoseListener != nil && firehoseMux != nil
+}
+
+func IsTxStreamRunning() bool {
+	return txStreamListener != nil && txStreamMux != nil
+}
+
 func HookupHTTP(chainId string, httpHandler *rpc.Server) error {
 	if httpMux != nil {
 		log.Infof("Hookup HTTP for (chainId, http Handler): (%v, %v)", chainId, httpHandler)
 		if httpHandler != nil {
-			httpMux.Handle("/"+chainId, httpHandler)
+			httpMux.Handle("/"+chainId, pausable(chainId, httpHandler))
 			httpHandlerMapping[chainId] = httpHandler
 		}
 	}
@@ -97,13 +146,56 @@ func HookupWS(chainId string, wsHandler *rpc.Server) error {
 	if wsMux != nil {
 		log.Infof("Hookup WS for (chainId, ws Handler): (%v, %v)", chainId, wsHandler)
 		if wsHandler != nil {
-			wsMux.Handle("/"+chainId, wsHandler.WebsocketHandler(wsOrigins))
+			wsMux.Handle("/"+chainId, pausable(chainId, wsHandler.WebsocketHandler(wsOrigins)))
 			wsHandlerMapping[chainId] = wsHandler
 		}
 	}
 	return nil
 }
 
+// PauseChain pauses the HTTP and WS RPC of the chain, the requests are rejected with 503 Service Unavailable until
+// the RPC is resumed
+func PauseChain(chainId string, paused bool) {
+	if paused {
+		pausedChains.Store(chainId, struct{}{})
+	} else {
+		pausedChains.Delete(chainId)
+	}
+}
+
+// pausable rejects the requests to the chain while its RPC is paused
+func pausable(chainId string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, paused := pausedChains.Load(chainId); paused {
+			http.Error(w, fmt.Sprintf("chain %s is paused", chainId), http.StatusServiceUnavailable)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// HookupFirehose serves the firehose stream of the chain on /chainId
+func HookupFirehose(chainId string, handler http.Handler) error {
+	if firehoseMux != nil {
+		log.Infof("Hookup Firehose for chainId: %v", chainId)
+		if handler != nil {
+			firehoseMux.Handle("/"+chainId, handler)
+		}
+	}
+	return nil
+}
+
+// HookupTxStream serves the transaction streams of the chain on /chainId
+func HookupTxStream(chainId string, handler http.Handler) error {
+	if txStreamMux != nil {
+		log.Infof("Hookup Tx Stream for chainId: %v", chainId)
+		if handler != nil {
+			txStreamMux.Handle("/"+chainId, handler)
+		}
+	}
+	return nil
+}
+
 func startHTTP(endpoint string, cors []string, vhosts []string, timeouts rpc.HTTPTimeouts) error {
 	// Short circuit if the HTTP endpoint isn't being exposed
 	if endpoint == "" {
@@ -164,3 +256,91 @@ func startPChainWSEndpoint(endpoint string) (net.Listener, *http.ServeMux, error
 	go wsServer.Serve(listener)
 	return listener, mux, err
 }
+
+// startFirehose starts the listener of the firehose streams, the server has no write timeout since the streams are
+// long-lived
+func startFirehose(endpoint string) error {
+	// Short circuit if the Firehose endpoint isn't being exposed
+	if endpoint == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return err
+	}
+	firehoseListener, firehoseMux = listener, http.NewServeMux()
+	go (&http.Server{Handler: firehoseMux}).Serve(listener)
+
+	log.Info("Firehose endpoint opened", "url", fmt.Sprintf("http://%s", listener.Addr()))
+	return nil
+}
+
+// startTxStream starts the listener of the transaction streams of the producers, only the producers holding one of the
+// keys of the file are served
+func startTxStream(endpoint string, keysFile string) error {
+	// Short circuit if the Tx Stream endpoint isn't being exposed
+	if endpoint == "" {
+		return nil
+	}
+	keys, err := loadProducerKeys(keysFile)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return err
+	}
+	txStreamListener, txStreamMux = listener, http.NewServeMux()
+	go (&http.Server{Handler: producerAuth(keys, txStreamMux)}).Serve(listener)
+
+	log.Info("Tx stream endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()), "producers", len(keys))
+	return nil
+}
+
+// loadProducerKeys reads the keys of the producers, one per line, the blank lines and the lines starting with # are
+// skipped. Only the hashes of the keys are kept.
+func loadProducerKeys(path string) ([][sha256.Size]byte, error) {
+	if path == "" {
+		return nil, errors.New("the tx stream requires the keys of the producers")
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var keys [][sha256.Size]byte
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if key := strings.TrimSpace(scanner.Text()); key != "" && !strings.HasPrefix(key, "#") {
+			keys = append(keys, sha256.Sum256([]byte(key)))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no producer key in %s", path)
+	}
+	return keys, nil
+}
+
+// producerAuth serves the requests carrying one of the keys in the X-Api-Key header, the hashes of the keys are
+// compared in constant time
+func producerAuth(keys [][sha256.Size]byte, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sum := sha256.Sum256([]byte(r.Header.Get("X-Api-Key")))
+		authorized := 0
+		for _, key := range keys {
+			authorized |= subtle.ConstantTimeCompare(sum[:], key[:])
+		}
+		if authorized == 0 {
+			log.Warn("Tx stream producer unauthorized", "remote", r.RemoteAddr)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,135 @@
+// Package vendorcheck hashes the consensus critical files of the vendored go-ethereum and compares them against the
+// manifest committed with each release, so that a binary built from the accidentally patched sources is told apart.
+//
+// The manifest is in the format of sha256sum, it could be checked with "sha256sum -c" as well, and the digest of the
+// sources is the sha256 of the manifest. The build computes the same digest with:
+//
+//	find <ConsensusPaths> -name '*.go' ! -name '*_test.go' | LC_ALL=C sort | xargs sha256sum | sha256sum
+package vendorcheck
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestFile is the manifest of the release, relative to the root of the repository
+const ManifestFile = "version/consensus.manifest"
+
+// ConsensusPaths are the directories of the consensus critical sources, relative to the root of the repository,
+// core includes the state and the vm
+var ConsensusPaths = []string{
+	"vendor/github.com/ethereum/go-ethereum/consensus/tendermint",
+	"vendor/github.com/ethereum/go-ethereum/core",
+}
+
+// Entry is the hash of the source file
+type Entry struct {
+	Path string // slash separated, relative to the root of the repository
+	Hash string // hex sha256 of the content
+}
+
+// Manifest is the hashes of the consensus critical sources, sorted by path
+type Manifest []Entry
+
+// Hash hashes the Go sources, except the tests, under the consensus paths of the repository root
+func Hash(root string) (Manifest, error) {
+	var manifest Manifest
+	for _, dir := range ConsensusPaths {
+		err := filepath.Walk(filepath.Join(root, filepath.FromSlash(dir)), func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			sum := sha256.Sum256(content)
+			manifest = append(manifest, Entry{Path: filepath.ToSlash(rel), Hash: hex.EncodeToString(sum[:])})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(manifest, func(i, j int) bool { return manifest[i].Path < manifest[j].Path })
+	return manifest, nil
+}
+
+// Load reads the manifest file
+func Load(file string) (Manifest, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		if scanner.Text() == "" {
+			continue
+		}
+		fields := strings.SplitN(scanner.Text(), "  ", 2)
+		if len(fields) != 2 || len(fields[0]) != 2*sha256.Size {
+			return nil, fmt.Errorf("%s:%d: malformed manifest entry", file, line)
+		}
+		manifest = append(manifest, Entry{Path: fields[1], Hash: fields[0]})
+	}
+	sort.Slice(manifest, func(i, j int) bool { return manifest[i].Path < manifest[j].Path })
+	return manifest, scanner.Err()
+}
+
+// Bytes returns the manifest in the format of sha256sum
+func (m Manifest) Bytes() []byte {
+	var buf bytes.Buffer
+	for _, entry := range m {
+		fmt.Fprintf(&buf, "%s  %s\n", entry.Hash, entry.Path)
+	}
+	return buf.Bytes()
+}
+
+// Digest returns the hex sha256 of the manifest, which identifies the consensus code
+func (m Manifest) Digest() string {
+	sum := sha256.Sum256(m.Bytes())
+	return hex.EncodeToString(sum[:])
+}
+
+// Diff returns the files of the sources modified, added or removed against the manifest of the release
+func Diff(release, sources Manifest) []string {
+	hashes := make(map[string]string, len(release))
+	for _, entry := range release {
+		hashes[entry.Path] = entry.Hash
+	}
+
+	var diff []string
+	for _, entry := range sources {
+		hash, ok := hashes[entry.Path]
+		switch {
+		case !ok:
+			diff = append(diff, "added    "+entry.Path)
+		case hash != entry.Hash:
+			diff = append(diff, "modified "+entry.Path)
+		}
+		delete(hashes, entry.Path)
+	}
+	for _, entry := range release {
+		if _, ok := hashes[entry.Path]; ok {
+			diff = append(diff, "removed  "+entry.Path)
+		}
+	}
+	return diff
+}
@@ -17,6 +17,10 @@ var (
 
 	// GitCommit is set with --ldflags "-X main.gitCommit=$(git rev-parse HEAD)"
 	GitCommit string
+
+	// ConsensusDigest is the digest of the consensus critical sources the binary is built from, set by build/env.sh
+	// with --ldflags "-X github.com/pchain/version.ConsensusDigest=...", empty if built without it
+	ConsensusDigest string
 )
 
 func init() {